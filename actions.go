@@ -1,6 +1,10 @@
 package easypanel
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"time"
+)
 
 // ActionsService handles action/deployment tracking API operations.
 type ActionsService struct {
@@ -10,6 +14,9 @@ type ActionsService struct {
 // List returns all actions for a project/service.
 func (s *ActionsService) List(ctx context.Context, params ListActionsParams) (RestResponse[[]Action], error) {
 	var resp RestResponse[[]Action]
+	if err := s.client.require(routeListActions, hasActions); err != nil {
+		return resp, err
+	}
 	err := s.client.get(ctx, routeListActions, params, &resp)
 	return resp, err
 }
@@ -17,6 +24,38 @@ func (s *ActionsService) List(ctx context.Context, params ListActionsParams) (Re
 // Get returns detailed information about a specific action.
 func (s *ActionsService) Get(ctx context.Context, params GetActionParams) (RestResponse[ActionDetail], error) {
 	var resp RestResponse[ActionDetail]
+	if err := s.client.require(routeGetAction, hasActions); err != nil {
+		return resp, err
+	}
 	err := s.client.get(ctx, routeGetAction, params, &resp)
 	return resp, err
 }
+
+// Wait polls an action every interval until it is no longer running and returns
+// its final details. It returns an error if the action ends with status "error"
+// or the context is done first. A non-positive interval defaults to 5s.
+func (s *ActionsService) Wait(ctx context.Context, params GetActionParams, interval time.Duration) (ActionDetail, error) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		resp, err := s.Get(ctx, params)
+		if err != nil {
+			return ActionDetail{}, err
+		}
+		detail := resp.Result.Data.JSON
+		switch detail.Status {
+		case "done":
+			return detail, nil
+		case "error":
+			return detail, fmt.Errorf("easypanel: action %s failed: %s", detail.ID, detail.Description)
+		}
+		select {
+		case <-ctx.Done():
+			return detail, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package easypanel
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionsWait(t *testing.T) {
+	calls := 0
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/trpc/actions.getAction", r.URL.Path)
+
+		var input GetActionParams
+		decodeTRPCQuery(t, r, &input)
+		assert.Equal(t, "act-1", input.ID)
+
+		calls++
+		status := "running"
+		if calls == 3 {
+			status = "done"
+		}
+		writeJSON(t, w, newRestResponse(ActionDetail{
+			Action: Action{ID: "act-1", Status: status},
+			Log:    "ok",
+		}))
+	})
+
+	detail, err := client.Actions.Wait(context.Background(), GetActionParams{ID: "act-1"}, time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, "done", detail.Status)
+	assert.Equal(t, 3, calls)
+}
+
+func TestActionsWait_Error(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, newRestResponse(ActionDetail{
+			Action: Action{ID: "act-1", Status: "error", Description: "restore volume"},
+		}))
+	})
+
+	_, err := client.Actions.Wait(context.Background(), GetActionParams{ID: "act-1"}, time.Millisecond)
+	assert.ErrorContains(t, err, "action act-1 failed")
+}
+
+func TestActionsWait_ContextDone(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, newRestResponse(ActionDetail{Action: Action{ID: "act-1", Status: "running"}}))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Actions.Wait(ctx, GetActionParams{ID: "act-1"}, time.Hour)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestActionsWait_ZeroInterval(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, newRestResponse(ActionDetail{Action: Action{ID: "act-1", Status: "done"}}))
+	})
+
+	detail, err := client.Actions.Wait(context.Background(), GetActionParams{ID: "act-1"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "done", detail.Status)
+}
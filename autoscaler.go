@@ -0,0 +1,286 @@
+package easypanel
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// AutoscalePolicy describes how one service is scaled.
+type AutoscalePolicy struct {
+	Service     SelectService
+	Type        ServiceType
+	MinReplicas int
+	MaxReplicas int
+	// TargetCPUPercent is the desired average CPU usage per container, where 100
+	// is one full core. Zero disables CPU-based scaling.
+	TargetCPUPercent float64
+	// TargetMemoryPercent is the desired average memory usage per container.
+	// Zero disables memory-based scaling.
+	TargetMemoryPercent float64
+	// Tolerance is the hysteresis band as a fraction of the target: no scaling
+	// happens while usage is within target*(1±Tolerance). Defaults to 0.1.
+	Tolerance float64
+	// ScaleUpCooldown and ScaleDownCooldown are the minimum times between
+	// scaling actions in each direction. They default to 1 and 5 minutes.
+	ScaleUpCooldown   time.Duration
+	ScaleDownCooldown time.Duration
+}
+
+// Validate checks that the policy is usable.
+func (p AutoscalePolicy) Validate() error {
+	switch {
+	case p.Service.ProjectName == "" || p.Service.ServiceName == "":
+		return fmt.Errorf("easypanel: autoscale policy needs a project and service name")
+	case p.MinReplicas < 1 || p.MaxReplicas < p.MinReplicas:
+		return fmt.Errorf("easypanel: autoscale policy for %s needs 1 <= MinReplicas <= MaxReplicas", p.Service.ServiceName)
+	case p.TargetCPUPercent <= 0 && p.TargetMemoryPercent <= 0:
+		return fmt.Errorf("easypanel: autoscale policy for %s needs a CPU or memory target", p.Service.ServiceName)
+	}
+	return nil
+}
+
+func (p AutoscalePolicy) withDefaults() AutoscalePolicy {
+	if p.Type == "" {
+		p.Type = ServiceTypeApp
+	}
+	if p.Tolerance == 0 {
+		p.Tolerance = 0.1
+	}
+	if p.ScaleUpCooldown == 0 {
+		p.ScaleUpCooldown = time.Minute
+	}
+	if p.ScaleDownCooldown == 0 {
+		p.ScaleDownCooldown = 5 * time.Minute
+	}
+	return p
+}
+
+// AutoscaleEvent records a scaling decision.
+type AutoscaleEvent struct {
+	Time          time.Time
+	Service       SelectService
+	From          int
+	To            int
+	CPUPercent    float64 // Average per container
+	MemoryPercent float64 // Average per container
+	Reason        string
+	DryRun        bool
+	Err           error
+}
+
+// AutoscalerOptions configures an Autoscaler.
+type AutoscalerOptions struct {
+	Interval  time.Duration // Time between evaluations; defaults to 30 seconds
+	DryRun    bool          // Record decisions without changing replicas
+	OnEvent   func(AutoscaleEvent)
+	MaxEvents int // Events kept in the log; defaults to 1000
+}
+
+// Autoscaler adjusts service replicas based on container statistics. Run it
+// in a goroutine of a long-lived process; state is kept in memory only.
+type Autoscaler struct {
+	client   *Client
+	policies []AutoscalePolicy
+	opts     AutoscalerOptions
+	now      func() time.Time
+
+	mu        sync.Mutex
+	lastScale map[SelectService]time.Time
+	blocked   map[SelectService]int // Replica count last held back by a cooldown
+	events    []AutoscaleEvent
+}
+
+// NewAutoscaler returns an Autoscaler for the given policies.
+func NewAutoscaler(client *Client, policies []AutoscalePolicy, opts AutoscalerOptions) (*Autoscaler, error) {
+	policies = append([]AutoscalePolicy(nil), policies...)
+	for i, p := range policies {
+		if err := p.Validate(); err != nil {
+			return nil, err
+		}
+		policies[i] = p.withDefaults()
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 30 * time.Second
+	}
+	if opts.MaxEvents == 0 {
+		opts.MaxEvents = 1000
+	}
+	return &Autoscaler{
+		client:    client,
+		policies:  policies,
+		opts:      opts,
+		now:       time.Now,
+		lastScale: make(map[SelectService]time.Time),
+		blocked:   make(map[SelectService]int),
+	}, nil
+}
+
+// Run evaluates all policies every interval until the context is done.
+// Errors from individual evaluations are recorded as events, not returned.
+func (a *Autoscaler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(a.opts.Interval)
+	defer ticker.Stop()
+	for {
+		a.Step(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Events returns a copy of the event log, oldest first.
+func (a *Autoscaler) Events() []AutoscaleEvent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]AutoscaleEvent(nil), a.events...)
+}
+
+// Step performs a single evaluation of all policies and returns the events it
+// produced.
+func (a *Autoscaler) Step(ctx context.Context) []AutoscaleEvent {
+	var events []AutoscaleEvent
+	stats, err := a.client.Monitor.GetMonitorTableData(ctx)
+	if err != nil {
+		for _, p := range a.policies {
+			events = append(events, a.record(AutoscaleEvent{Service: p.Service, Reason: "fetch container stats", Err: err}))
+		}
+		return events
+	}
+	for _, p := range a.policies {
+		if ev, ok := a.evaluate(ctx, p, stats.Result.Data.JSON); ok {
+			events = append(events, a.record(ev))
+		}
+	}
+	return events
+}
+
+// evaluate decides on and applies scaling for one policy. It returns false
+// when nothing noteworthy happened. A service without containers is only
+// scaled when its replica count is below MinReplicas, e.g. scaled to zero.
+func (a *Autoscaler) evaluate(ctx context.Context, p AutoscalePolicy, stats []ContainerStats) (AutoscaleEvent, bool) {
+	ev := AutoscaleEvent{Service: p.Service, DryRun: a.opts.DryRun}
+
+	var containers int
+	for _, c := range stats {
+		if c.ProjectName == p.Service.ProjectName && c.ServiceName == p.Service.ServiceName {
+			containers++
+			ev.CPUPercent += c.Stats.CPU.Percent
+			ev.MemoryPercent += c.Stats.Memory.Percent
+		}
+	}
+	if containers > 0 {
+		ev.CPUPercent /= float64(containers)
+		ev.MemoryPercent /= float64(containers)
+	}
+
+	svc, err := a.client.Services.Inspect(ctx, p.Type, p.Service)
+	if err != nil {
+		ev.Reason, ev.Err = "inspect service", err
+		return ev, true
+	}
+	// Without the current deploy settings an update would reset the command
+	// and other settings, so the service is left alone.
+	if svc.Result.Data.JSON.Deploy == nil {
+		ev.Reason, ev.Err = "skipped", fmt.Errorf("easypanel: service %s/%s reports no deploy settings", p.Service.ProjectName, p.Service.ServiceName)
+		return ev, true
+	}
+	deploy := *svc.Result.Data.JSON.Deploy
+	deploy.SelectService = p.Service
+	ev.From = deploy.Replicas
+	if containers == 0 && ev.From >= p.MinReplicas {
+		return ev, false
+	}
+
+	// Like Kubernetes' HPA, take the largest count any metric asks for, so
+	// scaling down only happens when every enabled metric agrees.
+	ev.To = 0
+	if p.TargetCPUPercent > 0 {
+		ev.To = max(ev.To, desiredReplicas(ev.From, ev.CPUPercent, p.TargetCPUPercent, p.Tolerance))
+	}
+	if p.TargetMemoryPercent > 0 {
+		ev.To = max(ev.To, desiredReplicas(ev.From, ev.MemoryPercent, p.TargetMemoryPercent, p.Tolerance))
+	}
+	ev.To = min(max(ev.To, p.MinReplicas), p.MaxReplicas)
+	if ev.To == ev.From {
+		a.mu.Lock()
+		delete(a.blocked, p.Service)
+		a.mu.Unlock()
+		return ev, false
+	}
+
+	// A count held back by the cooldown is reported once, not on every step.
+	cooldown := p.ScaleUpCooldown
+	if ev.To < ev.From {
+		cooldown = p.ScaleDownCooldown
+	}
+	a.mu.Lock()
+	last, scaled := a.lastScale[p.Service]
+	inCooldown := scaled && a.now().Sub(last) < cooldown
+	reported := false
+	if inCooldown {
+		held, ok := a.blocked[p.Service]
+		reported = ok && held == ev.To
+		a.blocked[p.Service] = ev.To
+	}
+	a.mu.Unlock()
+	if inCooldown {
+		if reported {
+			return ev, false
+		}
+		ev.Reason = fmt.Sprintf("cooldown: wanted %d replicas, last scaled %s ago", ev.To, a.now().Sub(last).Round(time.Second))
+		ev.To = ev.From
+		return ev, true
+	}
+
+	if ev.From < p.MinReplicas {
+		ev.Reason = fmt.Sprintf("%d replicas, below the minimum of %d", ev.From, p.MinReplicas)
+	} else {
+		ev.Reason = fmt.Sprintf("cpu %.1f%% (target %.1f%%), memory %.1f%% (target %.1f%%)", ev.CPUPercent, p.TargetCPUPercent, ev.MemoryPercent, p.TargetMemoryPercent)
+	}
+	if !a.opts.DryRun {
+		deploy.Replicas = ev.To
+		if err := a.client.Services.UpdateDeploy(ctx, p.Type, deploy); err != nil {
+			ev.Err = err
+			return ev, true
+		}
+		if err := a.client.Services.Deploy(ctx, p.Type, p.Service); err != nil {
+			ev.Err = err
+			return ev, true
+		}
+	}
+	a.mu.Lock()
+	a.lastScale[p.Service] = a.now()
+	delete(a.blocked, p.Service)
+	a.mu.Unlock()
+	return ev, true
+}
+
+// desiredReplicas returns the replica count that brings usage to target,
+// or current if usage is within the tolerance band.
+func desiredReplicas(current int, usage, target, tolerance float64) int {
+	ratio := usage / target
+	if math.Abs(ratio-1) <= tolerance {
+		return current
+	}
+	return int(math.Ceil(float64(current) * ratio))
+}
+
+// record stamps the event, appends it to the log and notifies OnEvent.
+func (a *Autoscaler) record(ev AutoscaleEvent) AutoscaleEvent {
+	ev.Time = a.now()
+	a.mu.Lock()
+	a.events = append(a.events, ev)
+	if len(a.events) > a.opts.MaxEvents {
+		a.events = a.events[len(a.events)-a.opts.MaxEvents:]
+	}
+	a.mu.Unlock()
+	if a.opts.OnEvent != nil {
+		a.opts.OnEvent(ev)
+	}
+	return ev
+}
@@ -0,0 +1,191 @@
+package easypanel
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSwarm serves monitor, inspect, updateDeploy and deploy for a single
+// "proj/web" service whose containers all report the same usage.
+type fakeSwarm struct {
+	mu         sync.Mutex
+	replicas   int
+	cpuPercent float64
+	memPercent float64
+	deploys    int
+	noDeploy   bool // inspect reports no deploy settings
+}
+
+func (f *fakeSwarm) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		switch r.URL.Path {
+		case "/api/trpc/monitor.getMonitorTableData":
+			var stats []ContainerStats
+			for i := 0; i < f.replicas; i++ {
+				c := containerSample("proj", "web", f.cpuPercent, 100)
+				c.Stats.Memory.Percent = f.memPercent
+				stats = append(stats, c)
+			}
+			writeJSON(t, w, newRestResponse(stats))
+		case "/api/trpc/services.app.inspectService":
+			if f.noDeploy {
+				writeJSON(t, w, newRestResponse(Service{}))
+				return
+			}
+			writeJSON(t, w, newRestResponse(Service{Deploy: &DeployParams{Replicas: f.replicas, Command: []string{"serve"}}}))
+		case "/api/trpc/services.app.updateDeploy":
+			var body DeployParams
+			decodeTRPCBody(t, r, &body)
+			assert.Equal(t, []string{"serve"}, body.Command, "other deploy settings are preserved")
+			f.replicas = body.Replicas
+			w.WriteHeader(http.StatusOK)
+		case "/api/trpc/services.app.deployService":
+			f.deploys++
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Fatalf("unexpected request %s", r.URL.Path)
+		}
+	}
+}
+
+func newTestAutoscaler(t *testing.T, swarm *fakeSwarm, opts AutoscalerOptions) (*Autoscaler, *time.Time) {
+	client := setupTestClient(t, swarm.handler(t))
+	a, err := NewAutoscaler(client, []AutoscalePolicy{{
+		Service:          SelectService{ProjectName: "proj", ServiceName: "web"},
+		MinReplicas:      1,
+		MaxReplicas:      5,
+		TargetCPUPercent: 50,
+	}}, opts)
+	require.NoError(t, err)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+	return a, &now
+}
+
+func TestDesiredReplicas(t *testing.T) {
+	assert.Equal(t, 2, desiredReplicas(2, 54, 50, 0.1), "within tolerance")
+	assert.Equal(t, 4, desiredReplicas(2, 100, 50, 0.1))
+	assert.Equal(t, 1, desiredReplicas(4, 10, 50, 0.1))
+	assert.Equal(t, 3, desiredReplicas(2, 70, 50, 0.1))
+}
+
+func TestAutoscalerStep(t *testing.T) {
+	swarm := &fakeSwarm{replicas: 2, cpuPercent: 100}
+	var seen []AutoscaleEvent
+	a, now := newTestAutoscaler(t, swarm, AutoscalerOptions{OnEvent: func(ev AutoscaleEvent) { seen = append(seen, ev) }})
+	ctx := context.Background()
+
+	events := a.Step(ctx)
+	require.Len(t, events, 1)
+	assert.Equal(t, 2, events[0].From)
+	assert.Equal(t, 4, events[0].To)
+	assert.NoError(t, events[0].Err)
+	assert.Equal(t, 4, swarm.replicas)
+	assert.Equal(t, 1, swarm.deploys)
+
+	// Still hot: capped at MaxReplicas but blocked by the scale-up cooldown.
+	*now = now.Add(30 * time.Second)
+	events = a.Step(ctx)
+	require.Len(t, events, 1)
+	assert.Contains(t, events[0].Reason, "cooldown: wanted 5 replicas")
+	assert.Equal(t, 4, swarm.replicas)
+	*now = now.Add(10 * time.Second)
+	assert.Empty(t, a.Step(ctx), "the same held-back decision is reported once")
+
+	*now = now.Add(time.Minute)
+	events = a.Step(ctx)
+	require.Len(t, events, 1)
+	assert.Equal(t, 5, events[0].To)
+
+	// Within the tolerance band nothing happens.
+	swarm.cpuPercent = 52
+	*now = now.Add(time.Hour)
+	assert.Empty(t, a.Step(ctx))
+
+	// Idle: scale down to the minimum.
+	swarm.cpuPercent = 5
+	events = a.Step(ctx)
+	require.Len(t, events, 1)
+	assert.Equal(t, 1, events[0].To)
+	assert.Equal(t, 1, swarm.replicas)
+
+	assert.Len(t, a.Events(), 4)
+	assert.Equal(t, a.Events(), seen)
+}
+
+func TestAutoscalerDryRun(t *testing.T) {
+	swarm := &fakeSwarm{replicas: 1, cpuPercent: 200}
+	a, _ := newTestAutoscaler(t, swarm, AutoscalerOptions{DryRun: true})
+
+	events := a.Step(context.Background())
+	require.Len(t, events, 1)
+	assert.True(t, events[0].DryRun)
+	assert.Equal(t, 4, events[0].To)
+	assert.Equal(t, 1, swarm.replicas)
+	assert.Zero(t, swarm.deploys)
+}
+
+func TestAutoscalerMemoryTarget(t *testing.T) {
+	swarm := &fakeSwarm{replicas: 2, cpuPercent: 5, memPercent: 90}
+	client := setupTestClient(t, swarm.handler(t))
+	a, err := NewAutoscaler(client, []AutoscalePolicy{{
+		Service:             SelectService{ProjectName: "proj", ServiceName: "web"},
+		MinReplicas:         1,
+		MaxReplicas:         10,
+		TargetCPUPercent:    50,
+		TargetMemoryPercent: 60,
+	}}, AutoscalerOptions{})
+	require.NoError(t, err)
+
+	events := a.Step(context.Background())
+	require.Len(t, events, 1)
+	assert.Equal(t, 3, events[0].To, "memory pressure wins over idle CPU")
+}
+
+func TestAutoscalerScaleFromZero(t *testing.T) {
+	swarm := &fakeSwarm{replicas: 0}
+	a, _ := newTestAutoscaler(t, swarm, AutoscalerOptions{})
+
+	events := a.Step(context.Background())
+	require.Len(t, events, 1)
+	assert.Equal(t, 0, events[0].From)
+	assert.Equal(t, 1, events[0].To)
+	assert.Contains(t, events[0].Reason, "below the minimum of 1")
+	assert.Equal(t, 1, swarm.replicas)
+}
+
+func TestAutoscalerSkipsServiceWithoutDeploy(t *testing.T) {
+	swarm := &fakeSwarm{replicas: 2, cpuPercent: 100, noDeploy: true}
+	a, _ := newTestAutoscaler(t, swarm, AutoscalerOptions{})
+
+	events := a.Step(context.Background())
+	require.Len(t, events, 1)
+	assert.Equal(t, "skipped", events[0].Reason)
+	assert.ErrorContains(t, events[0].Err, "proj/web reports no deploy settings")
+	assert.Equal(t, 2, swarm.replicas)
+	assert.Zero(t, swarm.deploys)
+}
+
+func TestNewAutoscaler_KeepsPolicies(t *testing.T) {
+	policies := []AutoscalePolicy{{Service: SelectService{ProjectName: "p", ServiceName: "s"}, MinReplicas: 1, MaxReplicas: 2, TargetCPUPercent: 50}}
+	_, err := NewAutoscaler(nil, policies, AutoscalerOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, policies[0].Type, "the caller's policies are not modified")
+	assert.Zero(t, policies[0].Tolerance)
+}
+
+func TestAutoscalePolicyValidate(t *testing.T) {
+	_, err := NewAutoscaler(nil, []AutoscalePolicy{{Service: SelectService{ProjectName: "p", ServiceName: "s"}, MinReplicas: 3, MaxReplicas: 2, TargetCPUPercent: 50}}, AutoscalerOptions{})
+	assert.ErrorContains(t, err, "MinReplicas <= MaxReplicas")
+
+	_, err = NewAutoscaler(nil, []AutoscalePolicy{{Service: SelectService{ProjectName: "p", ServiceName: "s"}, MinReplicas: 1, MaxReplicas: 2}}, AutoscalerOptions{})
+	assert.ErrorContains(t, err, "CPU or memory target")
+}
@@ -0,0 +1,217 @@
+package easypanel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// BackupsService handles backup destination API operations.
+type BackupsService struct {
+	client *httpClient
+}
+
+// ListDestinations returns all configured backup destinations.
+func (s *BackupsService) ListDestinations(ctx context.Context) (RestResponse[[]BackupDestination], error) {
+	var resp RestResponse[[]BackupDestination]
+	if err := s.client.require(routeListBackupDestinations, hasBackups); err != nil {
+		return resp, err
+	}
+	err := s.client.get(ctx, routeListBackupDestinations, nil, &resp)
+	return resp, err
+}
+
+// CreateDestination creates a new backup destination.
+func (s *BackupsService) CreateDestination(ctx context.Context, params CreateBackupDestinationParams) (RestResponse[BackupDestination], error) {
+	var resp RestResponse[BackupDestination]
+	if err := params.Validate(); err != nil {
+		return resp, err
+	}
+	if err := s.client.require(routeCreateBackupDestination, hasBackups); err != nil {
+		return resp, err
+	}
+	err := s.client.post(ctx, routeCreateBackupDestination, params, &resp)
+	return resp, err
+}
+
+// UpdateDestination updates an existing backup destination.
+func (s *BackupsService) UpdateDestination(ctx context.Context, params UpdateBackupDestinationParams) error {
+	if params.ID == "" {
+		return fmt.Errorf("easypanel: backup destination id is required")
+	}
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	if err := s.client.require(routeUpdateBackupDestination, hasBackups); err != nil {
+		return err
+	}
+	return s.client.post(ctx, routeUpdateBackupDestination, params, nil)
+}
+
+// DeleteDestination deletes a backup destination by ID.
+func (s *BackupsService) DeleteDestination(ctx context.Context, params DeleteBackupDestinationParams) error {
+	if err := s.client.require(routeDeleteBackupDestination, hasBackups); err != nil {
+		return err
+	}
+	return s.client.post(ctx, routeDeleteBackupDestination, params, nil)
+}
+
+// TestDestination asks the panel to verify that it can write to the destination.
+// The destination does not need to be saved first.
+func (s *BackupsService) TestDestination(ctx context.Context, params BackupDestination) (RestResponse[BackupDestinationTestResult], error) {
+	var resp RestResponse[BackupDestinationTestResult]
+	if err := params.Validate(); err != nil {
+		return resp, err
+	}
+	if err := s.client.require(routeTestBackupDestination, hasBackups); err != nil {
+		return resp, err
+	}
+	err := s.client.post(ctx, routeTestBackupDestination, params, &resp)
+	return resp, err
+}
+
+// Validate checks that the destination carries the credentials its type requires.
+func (d BackupDestination) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("easypanel: backup destination name is required")
+	}
+	switch d.Type {
+	case BackupDestinationS3:
+		if d.S3 == nil {
+			return fmt.Errorf("easypanel: s3 credentials are required for destination %q", d.Name)
+		}
+		return d.S3.Validate()
+	default:
+		return fmt.Errorf("easypanel: unsupported backup destination type %q", d.Type)
+	}
+}
+
+// Validate checks that all required S3 connection settings are present.
+func (c S3Credentials) Validate() error {
+	var missing []string
+	if c.Endpoint == "" {
+		missing = append(missing, "endpoint")
+	}
+	if c.Bucket == "" {
+		missing = append(missing, "bucket")
+	}
+	if c.AccessKeyID == "" {
+		missing = append(missing, "accessKeyId")
+	}
+	if c.SecretAccessKey == "" {
+		missing = append(missing, "secretAccessKey")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("easypanel: s3 credentials missing %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Validate checks the backup configuration for obvious mistakes before it is
+// sent to the panel. A disabled configuration is always valid.
+func (b BackupConfig) Validate() error {
+	if !b.Enabled {
+		return nil
+	}
+	if b.DestinationID == "" {
+		return fmt.Errorf("easypanel: backup destination is required")
+	}
+	if err := validateCron(b.Schedule); err != nil {
+		return err
+	}
+	if b.Retention < 0 {
+		return fmt.Errorf("easypanel: backup retention must not be negative, got %d", b.Retention)
+	}
+	return nil
+}
+
+// validateCron performs a shallow check of a standard five-field cron expression.
+// Each field may only contain digits and the characters "*", "/", "-" and ",".
+func validateCron(expr string) error {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return fmt.Errorf("easypanel: invalid cron schedule %q: expected 5 fields, got %d", expr, len(fields))
+	}
+	for _, f := range fields {
+		for _, r := range f {
+			if (r < '0' || r > '9') && !strings.ContainsRune("*/-,", r) {
+				return fmt.Errorf("easypanel: invalid cron schedule %q: unexpected character %q", expr, r)
+			}
+		}
+	}
+	return nil
+}
+
+// ListVolumeSchedules returns the volume backup schedules configured for a service.
+func (s *BackupsService) ListVolumeSchedules(ctx context.Context, params SelectService) (RestResponse[[]VolumeBackupSchedule], error) {
+	var resp RestResponse[[]VolumeBackupSchedule]
+	if err := s.client.require(routeListVolumeBackupSchedules, hasVolumeBackups); err != nil {
+		return resp, err
+	}
+	err := s.client.get(ctx, routeListVolumeBackupSchedules, params, &resp)
+	return resp, err
+}
+
+// UpdateVolumeSchedule creates or replaces the backup schedule of a volume mount.
+func (s *BackupsService) UpdateVolumeSchedule(ctx context.Context, params UpdateVolumeBackupParams) error {
+	if params.VolumeName == "" {
+		return fmt.Errorf("easypanel: volume name is required")
+	}
+	if err := params.Backup.Validate(); err != nil {
+		return err
+	}
+	if err := s.client.require(routeUpdateVolumeBackup, hasVolumeBackups); err != nil {
+		return err
+	}
+	return s.client.post(ctx, routeUpdateVolumeBackup, params, nil)
+}
+
+// ListVolumeBackups returns the backups taken of a service's volume mounts.
+func (s *BackupsService) ListVolumeBackups(ctx context.Context, params SelectService) (RestResponse[[]VolumeBackup], error) {
+	var resp RestResponse[[]VolumeBackup]
+	if err := s.client.require(routeListVolumeBackups, hasVolumeBackups); err != nil {
+		return resp, err
+	}
+	err := s.client.get(ctx, routeListVolumeBackups, params, &resp)
+	return resp, err
+}
+
+// RunVolumeBackup triggers an on-demand backup of a volume mount. The returned
+// action can be passed to ActionsService.Wait to follow its progress.
+func (s *BackupsService) RunVolumeBackup(ctx context.Context, params VolumeSelect) (RestResponse[Action], error) {
+	var resp RestResponse[Action]
+	if params.VolumeName == "" {
+		return resp, fmt.Errorf("easypanel: volume name is required")
+	}
+	if err := s.client.require(routeRunVolumeBackup, hasVolumeBackups); err != nil {
+		return resp, err
+	}
+	err := s.client.post(ctx, routeRunVolumeBackup, params, &resp)
+	return resp, err
+}
+
+// RestoreVolumeBackup restores a volume mount from a previous backup. The returned
+// action can be passed to ActionsService.Wait to follow its progress.
+func (s *BackupsService) RestoreVolumeBackup(ctx context.Context, params RestoreVolumeBackupParams) (RestResponse[Action], error) {
+	var resp RestResponse[Action]
+	if params.VolumeName == "" || params.BackupID == "" {
+		return resp, fmt.Errorf("easypanel: volume name and backup id are required")
+	}
+	if err := s.client.require(routeRestoreVolumeBackup, hasVolumeBackups); err != nil {
+		return resp, err
+	}
+	err := s.client.post(ctx, routeRestoreVolumeBackup, params, &resp)
+	return resp, err
+}
+
+// VolumeMounts returns the service's mounts of type "volume", which are the
+// only mounts that can be backed up.
+func (s Service) VolumeMounts() []MountEntry {
+	var mounts []MountEntry
+	for _, m := range s.Mounts {
+		if m.Type == "volume" {
+			mounts = append(mounts, m)
+		}
+	}
+	return mounts
+}
@@ -0,0 +1,321 @@
+package easypanel
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     BackupConfig
+		wantErr string
+	}{
+		{name: "disabled", cfg: BackupConfig{}},
+		{name: "valid", cfg: BackupConfig{Enabled: true, Schedule: "0 3 * * *", DestinationID: "s3", Retention: 7}},
+		{name: "step and range", cfg: BackupConfig{Enabled: true, Schedule: "*/15 1-5 * * 1,3", DestinationID: "s3"}},
+		{name: "missing destination", cfg: BackupConfig{Enabled: true, Schedule: "0 3 * * *"}, wantErr: "destination is required"},
+		{name: "too few fields", cfg: BackupConfig{Enabled: true, Schedule: "0 3 * *", DestinationID: "s3"}, wantErr: "expected 5 fields"},
+		{name: "bad character", cfg: BackupConfig{Enabled: true, Schedule: "0 3 * * MON", DestinationID: "s3"}, wantErr: "unexpected character"},
+		{name: "negative retention", cfg: BackupConfig{Enabled: true, Schedule: "0 3 * * *", DestinationID: "s3", Retention: -1}, wantErr: "must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestServicesUpdateBackup(t *testing.T) {
+	params := UpdateBackupParams{
+		SelectService: SelectService{
+			ProjectName: "proj",
+			ServiceName: "db",
+		},
+		Backup: BackupConfig{
+			Enabled:       true,
+			Schedule:      "0 3 * * *",
+			DestinationID: "dest-1",
+			Prefix:        "proj/db",
+			Retention:     14,
+		},
+	}
+
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/trpc/services.postgres.updateBackup", r.URL.Path)
+
+		var body UpdateBackupParams
+		decodeTRPCBody(t, r, &body)
+		assert.Equal(t, params, body)
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	err := client.Services.UpdateBackup(context.Background(), ServiceTypePostgres, params)
+	require.NoError(t, err)
+}
+
+func TestServicesUpdateBackup_RejectsNonDatabase(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("no request expected")
+	})
+
+	ctx := context.Background()
+	err := client.Services.UpdateBackup(ctx, ServiceTypeApp, UpdateBackupParams{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not supported")
+
+	_, err = client.Services.ListBackups(ctx, ServiceTypeApp, SelectService{})
+	assert.ErrorContains(t, err, "not supported")
+	assert.ErrorContains(t, client.Services.RunBackup(ctx, ServiceTypeCompose, SelectService{}), "not supported")
+	assert.ErrorContains(t, client.Services.RestoreBackup(ctx, ServiceTypeApp, RestoreBackupParams{}), "not supported")
+}
+
+func TestServicesListBackups(t *testing.T) {
+	want := []Backup{
+		{ID: "b1", ProjectName: "proj", ServiceName: "db", Key: "proj/db/2025-01-01.sql.gz", Size: 2048, Status: "done"},
+	}
+
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/trpc/services.mysql.listBackups", r.URL.Path)
+
+		var input SelectService
+		decodeTRPCQuery(t, r, &input)
+		assert.Equal(t, "db", input.ServiceName)
+
+		writeJSON(t, w, newRestResponse(want))
+	})
+
+	resp, err := client.Services.ListBackups(context.Background(), ServiceTypeMySQL, SelectService{ProjectName: "proj", ServiceName: "db"})
+	require.NoError(t, err)
+	assert.Equal(t, want, resp.Result.Data.JSON)
+}
+
+func TestServicesRestoreBackup(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/trpc/services.mongo.restoreBackup", r.URL.Path)
+
+		var body RestoreBackupParams
+		decodeTRPCBody(t, r, &body)
+		assert.Equal(t, "b1", body.BackupID)
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	err := client.Services.RestoreBackup(context.Background(), ServiceTypeMongo, RestoreBackupParams{
+		SelectService: SelectService{ProjectName: "proj", ServiceName: "db"},
+		BackupID:      "b1",
+	})
+	require.NoError(t, err)
+}
+
+func localS3Destination() BackupDestination {
+	return BackupDestination{
+		Name: "minio",
+		Type: BackupDestinationS3,
+		S3: &S3Credentials{
+			Endpoint:        "http://localhost:9000",
+			Bucket:          "backups",
+			AccessKeyID:     "minioadmin",
+			SecretAccessKey: "minioadmin",
+			ForcePathStyle:  true,
+		},
+	}
+}
+
+func TestBackupDestinationValidate(t *testing.T) {
+	assert.NoError(t, localS3Destination().Validate())
+
+	noName := localS3Destination()
+	noName.Name = ""
+	assert.ErrorContains(t, noName.Validate(), "name is required")
+
+	noCreds := localS3Destination()
+	noCreds.S3 = nil
+	assert.ErrorContains(t, noCreds.Validate(), "s3 credentials are required")
+
+	partial := localS3Destination()
+	partial.S3.Bucket = ""
+	partial.S3.SecretAccessKey = ""
+	assert.ErrorContains(t, partial.Validate(), "missing bucket, secretAccessKey")
+
+	unknown := localS3Destination()
+	unknown.Type = "ftp"
+	assert.ErrorContains(t, unknown.Validate(), "unsupported backup destination type")
+}
+
+func TestBackupsCreateDestination(t *testing.T) {
+	dest := localS3Destination()
+
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/trpc/backups.createDestination", r.URL.Path)
+
+		var body BackupDestination
+		decodeTRPCBody(t, r, &body)
+		assert.Equal(t, dest, body)
+
+		body.ID = "dest-1"
+		writeJSON(t, w, newRestResponse(body))
+	})
+
+	resp, err := client.Backups.CreateDestination(context.Background(), dest)
+	require.NoError(t, err)
+	assert.Equal(t, "dest-1", resp.Result.Data.JSON.ID)
+	assert.Equal(t, "backups", resp.Result.Data.JSON.S3.Bucket)
+}
+
+func TestBackupsListDestinations(t *testing.T) {
+	dest := localS3Destination()
+	dest.ID = "dest-1"
+
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/trpc/backups.listDestinations", r.URL.Path)
+		writeJSON(t, w, newRestResponse([]BackupDestination{dest}))
+	})
+
+	resp, err := client.Backups.ListDestinations(context.Background())
+	require.NoError(t, err)
+	require.Len(t, resp.Result.Data.JSON, 1)
+	assert.Equal(t, dest, resp.Result.Data.JSON[0])
+}
+
+func TestBackupsUpdateDestination_RequiresID(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("no request expected")
+	})
+
+	err := client.Backups.UpdateDestination(context.Background(), localS3Destination())
+	assert.ErrorContains(t, err, "id is required")
+}
+
+func TestBackupsDeleteDestination(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/trpc/backups.deleteDestination", r.URL.Path)
+
+		var body DeleteBackupDestinationParams
+		decodeTRPCBody(t, r, &body)
+		assert.Equal(t, "dest-1", body.ID)
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	err := client.Backups.DeleteDestination(context.Background(), DeleteBackupDestinationParams{ID: "dest-1"})
+	require.NoError(t, err)
+}
+
+func TestBackupsTestDestination(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/trpc/backups.testDestination", r.URL.Path)
+
+		var body BackupDestination
+		decodeTRPCBody(t, r, &body)
+		assert.Equal(t, "http://localhost:9000", body.S3.Endpoint)
+		assert.True(t, body.S3.ForcePathStyle)
+
+		writeJSON(t, w, newRestResponse(BackupDestinationTestResult{OK: false, Message: "bucket not found"}))
+	})
+
+	resp, err := client.Backups.TestDestination(context.Background(), localS3Destination())
+	require.NoError(t, err)
+	assert.False(t, resp.Result.Data.JSON.OK)
+	assert.Equal(t, "bucket not found", resp.Result.Data.JSON.Message)
+}
+
+func TestBackupsUpdateVolumeSchedule(t *testing.T) {
+	params := UpdateVolumeBackupParams{
+		VolumeSelect: VolumeSelect{
+			SelectService: SelectService{ProjectName: "proj", ServiceName: "web"},
+			VolumeName:    "uploads",
+		},
+		Backup: BackupConfig{Enabled: true, Schedule: "0 4 * * *", DestinationID: "dest-1"},
+	}
+
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/trpc/volumeBackups.updateSchedule", r.URL.Path)
+
+		var body UpdateVolumeBackupParams
+		decodeTRPCBody(t, r, &body)
+		assert.Equal(t, params, body)
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	err := client.Backups.UpdateVolumeSchedule(context.Background(), params)
+	require.NoError(t, err)
+
+	err = client.Backups.UpdateVolumeSchedule(context.Background(), UpdateVolumeBackupParams{})
+	assert.ErrorContains(t, err, "volume name is required")
+}
+
+func TestBackupsRunVolumeBackup(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/trpc/volumeBackups.runBackup", r.URL.Path)
+
+		var body VolumeSelect
+		decodeTRPCBody(t, r, &body)
+		assert.Equal(t, "uploads", body.VolumeName)
+
+		writeJSON(t, w, newRestResponse(Action{ID: "act-1", Type: "volume_backup", Status: "running"}))
+	})
+
+	resp, err := client.Backups.RunVolumeBackup(context.Background(), VolumeSelect{
+		SelectService: SelectService{ProjectName: "proj", ServiceName: "web"},
+		VolumeName:    "uploads",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "act-1", resp.Result.Data.JSON.ID)
+}
+
+func TestBackupsRestoreVolumeBackup(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/trpc/volumeBackups.restoreBackup", r.URL.Path)
+
+		var body RestoreVolumeBackupParams
+		decodeTRPCBody(t, r, &body)
+		assert.Equal(t, "uploads", body.VolumeName)
+		assert.Equal(t, "vb-1", body.BackupID)
+
+		writeJSON(t, w, newRestResponse(Action{ID: "act-2", Status: "running"}))
+	})
+
+	resp, err := client.Backups.RestoreVolumeBackup(context.Background(), RestoreVolumeBackupParams{
+		VolumeSelect: VolumeSelect{
+			SelectService: SelectService{ProjectName: "proj", ServiceName: "web"},
+			VolumeName:    "uploads",
+		},
+		BackupID: "vb-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "act-2", resp.Result.Data.JSON.ID)
+}
+
+func TestServiceVolumeMounts(t *testing.T) {
+	svc := Service{Mounts: []MountEntry{
+		{Type: "bind", HostPath: "/srv", MountPath: "/srv"},
+		{Type: "volume", Name: "uploads", MountPath: "/app/uploads"},
+		{Type: "file", Content: "x", MountPath: "/etc/x"},
+	}}
+
+	got := svc.VolumeMounts()
+	require.Len(t, got, 1)
+	assert.Equal(t, "uploads", got[0].Name)
+}
@@ -0,0 +1,178 @@
+package easypanel
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+// PasswordOptions controls the strength of generated passwords.
+type PasswordOptions struct {
+	Length  int  // Defaults to 24; values below 8 are rejected
+	Symbols bool // Include punctuation in addition to letters and digits
+}
+
+// BasicAuthOptions configures the basic auth helpers.
+type BasicAuthOptions struct {
+	// Password controls generated passwords for RotateBasicAuthUser.
+	Password PasswordOptions
+	// Hash, if set, is applied to new passwords before they are sent. Use it for
+	// panels that accept pre-hashed htpasswd credentials, e.g. bcrypt.
+	Hash func(password string) (string, error)
+}
+
+const (
+	lowerChars  = "abcdefghijklmnopqrstuvwxyz"
+	upperChars  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars  = "0123456789"
+	symbolChars = "!#%+-.=@^_~"
+)
+
+// GeneratePassword returns a random password using crypto/rand. It always
+// contains at least one lowercase letter, uppercase letter and digit, and at
+// least one symbol if opts.Symbols is set.
+func GeneratePassword(opts PasswordOptions) (string, error) {
+	length := opts.Length
+	if length == 0 {
+		length = 24
+	}
+	if length < 8 {
+		return "", fmt.Errorf("easypanel: password length must be at least 8, got %d", length)
+	}
+	classes := []string{lowerChars, upperChars, digitChars}
+	if opts.Symbols {
+		classes = append(classes, symbolChars)
+	}
+	var all string
+	for _, c := range classes {
+		all += c
+	}
+
+	pw := make([]byte, length)
+	for i := range pw {
+		set := all
+		if i < len(classes) {
+			set = classes[i]
+		}
+		c, err := randomChar(set)
+		if err != nil {
+			return "", err
+		}
+		pw[i] = c
+	}
+	// Shuffle so the guaranteed characters are not always first.
+	for i := len(pw) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", fmt.Errorf("easypanel: generate password: %w", err)
+		}
+		pw[i], pw[j.Int64()] = pw[j.Int64()], pw[i]
+	}
+	return string(pw), nil
+}
+
+func randomChar(set string) (byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(set))))
+	if err != nil {
+		return 0, fmt.Errorf("easypanel: generate password: %w", err)
+	}
+	return set[n.Int64()], nil
+}
+
+// String returns the username with the password redacted, so credentials are
+// not leaked when users are logged.
+func (u UserParams) String() string {
+	return u.Username + ":***"
+}
+
+// AddBasicAuthUser adds a user to the service's basic auth list, keeping the
+// existing users. It fails if the password is empty or the username is
+// already present.
+func (s *ServicesService) AddBasicAuthUser(ctx context.Context, st ServiceType, sel SelectService, user UserParams, opts BasicAuthOptions) error {
+	if user.Password == "" {
+		return fmt.Errorf("easypanel: basic auth user %q needs a password", user.Username)
+	}
+	users, err := s.basicAuthUsers(ctx, st, sel)
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		if u.Username == user.Username {
+			return fmt.Errorf("easypanel: basic auth user %q already exists", user.Username)
+		}
+	}
+	if user.Password, err = opts.hash(user.Password); err != nil {
+		return err
+	}
+	return s.UpdateBasicAuth(ctx, st, UpdateBasicAuth{SelectService: sel, BasicAuth: append(users, user)})
+}
+
+// RemoveBasicAuthUser removes a user from the service's basic auth list. It
+// fails if the username is not present.
+func (s *ServicesService) RemoveBasicAuthUser(ctx context.Context, st ServiceType, sel SelectService, username string) error {
+	users, err := s.basicAuthUsers(ctx, st, sel)
+	if err != nil {
+		return err
+	}
+	kept := make([]UserParams, 0, len(users))
+	for _, u := range users {
+		if u.Username != username {
+			kept = append(kept, u)
+		}
+	}
+	if len(kept) == len(users) {
+		return fmt.Errorf("easypanel: basic auth user %q not found", username)
+	}
+	return s.UpdateBasicAuth(ctx, st, UpdateBasicAuth{SelectService: sel, BasicAuth: kept})
+}
+
+// RotateBasicAuthUser replaces the password of an existing user with a newly
+// generated one and returns the new plaintext password.
+func (s *ServicesService) RotateBasicAuthUser(ctx context.Context, st ServiceType, sel SelectService, username string, opts BasicAuthOptions) (string, error) {
+	users, err := s.basicAuthUsers(ctx, st, sel)
+	if err != nil {
+		return "", err
+	}
+	found := -1
+	for i := range users {
+		if users[i].Username == username {
+			found = i
+			break
+		}
+	}
+	if found < 0 {
+		return "", fmt.Errorf("easypanel: basic auth user %q not found", username)
+	}
+	password, err := GeneratePassword(opts.Password)
+	if err != nil {
+		return "", err
+	}
+	if users[found].Password, err = opts.hash(password); err != nil {
+		return "", err
+	}
+	if err := s.UpdateBasicAuth(ctx, st, UpdateBasicAuth{SelectService: sel, BasicAuth: users}); err != nil {
+		return "", err
+	}
+	return password, nil
+}
+
+// basicAuthUsers returns the service's current basic auth users.
+func (s *ServicesService) basicAuthUsers(ctx context.Context, st ServiceType, sel SelectService) ([]UserParams, error) {
+	resp, err := s.Inspect(ctx, st, sel)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Result.Data.JSON.BasicAuth, nil
+}
+
+func (o BasicAuthOptions) hash(password string) (string, error) {
+	if o.Hash == nil {
+		return password, nil
+	}
+	hashed, err := o.Hash(password)
+	if err != nil {
+		return "", fmt.Errorf("easypanel: hash password: %w", err)
+	}
+	return hashed, nil
+}
@@ -0,0 +1,107 @@
+package easypanel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratePassword(t *testing.T) {
+	pw, err := GeneratePassword(PasswordOptions{})
+	require.NoError(t, err)
+	assert.Len(t, pw, 24)
+	assert.True(t, strings.ContainsAny(pw, lowerChars))
+	assert.True(t, strings.ContainsAny(pw, upperChars))
+	assert.True(t, strings.ContainsAny(pw, digitChars))
+	assert.False(t, strings.ContainsAny(pw, symbolChars))
+
+	pw, err = GeneratePassword(PasswordOptions{Length: 8, Symbols: true})
+	require.NoError(t, err)
+	assert.Len(t, pw, 8)
+	assert.True(t, strings.ContainsAny(pw, symbolChars))
+
+	other, err := GeneratePassword(PasswordOptions{Length: 8, Symbols: true})
+	require.NoError(t, err)
+	assert.NotEqual(t, pw, other)
+
+	_, err = GeneratePassword(PasswordOptions{Length: 4})
+	assert.ErrorContains(t, err, "at least 8")
+}
+
+func TestUserParamsString(t *testing.T) {
+	u := UserParams{Username: "admin", Password: "hunter2"}
+	assert.Equal(t, "admin:***", u.String())
+	assert.NotContains(t, fmt.Sprintf("%v", []UserParams{u}), "hunter2")
+}
+
+// setupBasicAuthClient serves inspectService and updateBasicAuth from an
+// in-memory user list.
+func setupBasicAuthClient(t *testing.T, users *[]UserParams) *Client {
+	return setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/trpc/services.app.inspectService":
+			writeJSON(t, w, newRestResponse(Service{BasicAuth: *users}))
+		case "/api/trpc/services.app.updateBasicAuth":
+			var body UpdateBasicAuth
+			decodeTRPCBody(t, r, &body)
+			assert.Equal(t, "web", body.ServiceName)
+			*users = body.BasicAuth
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Fatalf("unexpected request %s", r.URL.Path)
+		}
+	})
+}
+
+func TestServicesBasicAuthUsers(t *testing.T) {
+	users := []UserParams{{Username: "alice", Password: "a"}}
+	client := setupBasicAuthClient(t, &users)
+	ctx := context.Background()
+	sel := SelectService{ProjectName: "proj", ServiceName: "web"}
+
+	err := client.Services.AddBasicAuthUser(ctx, ServiceTypeApp, sel, UserParams{Username: "bob", Password: "b"}, BasicAuthOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []UserParams{{Username: "alice", Password: "a"}, {Username: "bob", Password: "b"}}, users)
+
+	err = client.Services.AddBasicAuthUser(ctx, ServiceTypeApp, sel, UserParams{Username: "bob", Password: "x"}, BasicAuthOptions{})
+	assert.ErrorContains(t, err, `"bob" already exists`)
+
+	err = client.Services.AddBasicAuthUser(ctx, ServiceTypeApp, sel, UserParams{Username: "carol"}, BasicAuthOptions{})
+	assert.ErrorContains(t, err, `"carol" needs a password`)
+
+	pw, err := client.Services.RotateBasicAuthUser(ctx, ServiceTypeApp, sel, "alice", BasicAuthOptions{Password: PasswordOptions{Length: 16}})
+	require.NoError(t, err)
+	assert.Len(t, pw, 16)
+	assert.Equal(t, pw, users[0].Password)
+	assert.Equal(t, "b", users[1].Password)
+
+	err = client.Services.RemoveBasicAuthUser(ctx, ServiceTypeApp, sel, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, []UserParams{{Username: "bob", Password: "b"}}, users)
+
+	err = client.Services.RemoveBasicAuthUser(ctx, ServiceTypeApp, sel, "alice")
+	assert.ErrorContains(t, err, `"alice" not found`)
+
+	_, err = client.Services.RotateBasicAuthUser(ctx, ServiceTypeApp, sel, "carol", BasicAuthOptions{Password: PasswordOptions{Length: 4}})
+	assert.ErrorContains(t, err, `"carol" not found`, "the user is looked up before a password is generated")
+}
+
+func TestServicesBasicAuthUsers_Hash(t *testing.T) {
+	var users []UserParams
+	client := setupBasicAuthClient(t, &users)
+	opts := BasicAuthOptions{Hash: func(p string) (string, error) { return "hashed:" + p, nil }}
+	sel := SelectService{ProjectName: "proj", ServiceName: "web"}
+
+	err := client.Services.AddBasicAuthUser(context.Background(), ServiceTypeApp, sel, UserParams{Username: "bob", Password: "b"}, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "hashed:b", users[0].Password)
+
+	pw, err := client.Services.RotateBasicAuthUser(context.Background(), ServiceTypeApp, sel, "bob", opts)
+	require.NoError(t, err)
+	assert.Equal(t, "hashed:"+pw, users[0].Password)
+}
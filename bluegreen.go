@@ -0,0 +1,221 @@
+package easypanel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// BlueGreenOptions controls ServicesService.BlueGreen.
+type BlueGreenOptions struct {
+	// Type is the type of the live service. When empty it is looked up in the
+	// live service's project.
+	Type ServiceType
+	// Source and Env, when set, are applied to the standby service before it
+	// is deployed. Otherwise the standby keeps its current configuration,
+	// which for a newly created standby is that of the live service.
+	Source *ServiceSource
+	Env    *string
+	// HealthTimeout bounds the wait for the standby to run all its tasks.
+	// Defaults to 5m.
+	HealthTimeout time.Duration
+	// PollInterval is how often task stats are checked. Defaults to 5s.
+	PollInterval time.Duration
+	// Verify, when set, is called once the domains point at the standby. An
+	// error switches the domains back to the live service.
+	Verify func(ctx context.Context, standby SelectService) error
+	// StopPrevious stops the previously live service after a successful
+	// switch. By default it keeps running so traffic can be switched back
+	// instantly by calling BlueGreen again with the roles reversed.
+	StopPrevious bool
+}
+
+// BlueGreenResult describes a completed ServicesService.BlueGreen switch.
+type BlueGreenResult struct {
+	Type           ServiceType
+	Live           SelectService // The service now receiving traffic
+	Idle           SelectService // The previously live service
+	Domains        []Domain      // Domains repointed at Live
+	SkippedDomains []Domain      // Domains of the live service that do not route to a service and were left alone
+	CreatedStandby bool          // Whether the standby was cloned from the live service
+}
+
+// BlueGreen deploys a new version to standby, a sibling of the live service,
+// and moves the live service's domains to it once it is healthy:
+//
+//   - standby is cloned from live, without domains and published ports, if it
+//     does not exist
+//   - opts.Source and opts.Env are applied to standby and it is deployed
+//   - Monitor.GetDockerTaskStats is polled until all desired tasks of
+//     standby are running
+//   - every domain of live is repointed at standby with DomainsService.Update
+//   - opts.Verify is run and, if set, opts.StopPrevious stops live
+//
+// Domains are updated one at a time since the panel has no batch update. If
+// an update or Verify fails, domains already switched are pointed back at
+// live, so traffic returns to the previous version. Failures before the
+// switch leave the domains untouched. A standby cloned by this call is
+// destroyed again when the run fails before or during the switch. Live must
+// have at least one domain routing to a service in the domains API; other
+// domains are reported in SkippedDomains.
+func (s *ServicesService) BlueGreen(ctx context.Context, live, standby SelectService, opts BlueGreenOptions) (BlueGreenResult, error) {
+	result := BlueGreenResult{Type: opts.Type, Live: live, Idle: standby}
+	fail := func(step string, err error) (BlueGreenResult, error) {
+		return result, fmt.Errorf("easypanel: blue/green %s/%s to %s/%s: %s: %w", live.ProjectName, live.ServiceName, standby.ProjectName, standby.ServiceName, step, err)
+	}
+	if live.ProjectName == standby.ProjectName && live.ServiceName == standby.ServiceName {
+		return fail("check services", fmt.Errorf("live and standby are the same service"))
+	}
+	if opts.HealthTimeout <= 0 {
+		opts.HealthTimeout = 5 * time.Minute
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 5 * time.Second
+	}
+	if result.Type == "" {
+		st, err := s.lookupType(ctx, live)
+		if err != nil {
+			return result, err
+		}
+		result.Type = st
+	}
+	st := result.Type
+
+	domains := s.domains()
+	listed, err := domains.List(ctx, ListDomainsParams{ProjectName: live.ProjectName, ServiceName: live.ServiceName})
+	if err != nil {
+		return fail("list domains", err)
+	}
+	routed, skipped := splitServiceDomains(listed.Result.Data.JSON)
+	result.SkippedDomains = skipped
+	if len(routed) == 0 {
+		return fail("list domains", fmt.Errorf("live service has no domains to switch"))
+	}
+
+	standbyType, exists, err := s.findType(ctx, standby)
+	if err != nil {
+		return fail("find standby", err)
+	}
+	if exists && standbyType != st {
+		return fail("find standby", fmt.Errorf("standby has type %q, not %q", standbyType, st))
+	}
+	// abort fails the run and destroys the standby if this run created it.
+	abort := func(step string, err error) (BlueGreenResult, error) {
+		if result.CreatedStandby {
+			cleanupCtx, cancel := cleanupContext(ctx)
+			defer cancel()
+			if derr := s.Destroy(cleanupCtx, st, standby); derr != nil {
+				err = errors.Join(err, fmt.Errorf("destroy standby: %w", derr))
+			}
+		}
+		return fail(step, err)
+	}
+	if !exists {
+		cloned, err := s.Clone(ctx, live, standby, CloneOptions{Type: st, RewritePort: func(int) int { return 0 }})
+		result.CreatedStandby = cloned.Created
+		if err != nil {
+			return abort("clone", err)
+		}
+	}
+
+	if opts.Source != nil {
+		params, err := sourceParams(st, standby, *opts.Source, false)
+		if err != nil {
+			return abort("source", err)
+		}
+		if err := s.applySource(ctx, st, params); err != nil {
+			return abort("source", err)
+		}
+	}
+	if opts.Env != nil {
+		if err := s.UpdateEnv(ctx, st, UpdateEnv{SelectService: standby, Env: *opts.Env}); err != nil {
+			return abort("env", err)
+		}
+	}
+	if err := s.Deploy(ctx, st, standby); err != nil {
+		return abort("deploy", err)
+	}
+	healthCtx, cancel := context.WithTimeout(ctx, opts.HealthTimeout)
+	err = (&MonitorService{client: s.client}).WaitHealthy(healthCtx, standby, opts.PollInterval)
+	cancel()
+	if err != nil {
+		return abort("wait healthy", err)
+	}
+
+	switched, err := switchDomains(ctx, domains, routed, standby)
+	if err == nil && opts.Verify != nil {
+		if verr := opts.Verify(ctx, standby); verr != nil {
+			err = fmt.Errorf("verify: %w", verr)
+		}
+	}
+	if err != nil {
+		if rerr := restoreDomains(ctx, domains, routed[:len(switched)]); rerr != nil {
+			err = errors.Join(err, fmt.Errorf("switch back: %w", rerr))
+		}
+		return abort("switch domains", err)
+	}
+	result.Live, result.Idle, result.Domains = standby, live, switched
+
+	if opts.StopPrevious {
+		if err := s.Stop(ctx, st, live); err != nil {
+			return fail("stop previous", err)
+		}
+	}
+	return result, nil
+}
+
+// splitServiceDomains separates the domains that route to a service from the
+// others, such as domains with another destination type, which cannot be
+// repointed at a service.
+func splitServiceDomains(all []Domain) (routed, skipped []Domain) {
+	for _, d := range all {
+		if d.ServiceDestination == nil || (d.DestinationType != "" && d.DestinationType != DomainDestinationService) {
+			skipped = append(skipped, d)
+			continue
+		}
+		routed = append(routed, d)
+	}
+	return routed, skipped
+}
+
+// switchDomains points each domain at target, stopping at the first failure.
+// It returns the domains switched so far. All domains must have a
+// ServiceDestination; see splitServiceDomains.
+func switchDomains(ctx context.Context, domains *DomainsService, routed []Domain, target SelectService) ([]Domain, error) {
+	switched := make([]Domain, 0, len(routed))
+	for _, d := range routed {
+		dest := *d.ServiceDestination
+		dest.ProjectName, dest.ServiceName = target.ProjectName, target.ServiceName
+		d.ServiceDestination = &dest
+		if err := domains.Update(ctx, d); err != nil {
+			return switched, fmt.Errorf("domain %s: %w", d.Host, err)
+		}
+		switched = append(switched, d)
+	}
+	return switched, nil
+}
+
+// restoreDomains writes back the original domains, attempting every one. It
+// runs under cleanupContext, so it still completes when ctx was canceled.
+func restoreDomains(ctx context.Context, domains *DomainsService, original []Domain) error {
+	ctx, cancel := cleanupContext(ctx)
+	defer cancel()
+	var errs []error
+	for _, d := range original {
+		if err := domains.Update(ctx, d); err != nil {
+			errs = append(errs, fmt.Errorf("domain %s: %w", d.Host, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// cleanupTimeout bounds the rollback and cleanup requests of a failed switch.
+const cleanupTimeout = 2 * time.Minute
+
+// cleanupContext returns a context for undoing a failed operation. It keeps
+// the values of ctx but not its cancellation, since ctx is often what failed
+// the operation, and is bounded by cleanupTimeout instead.
+func cleanupContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+}
@@ -0,0 +1,206 @@
+package easypanel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	blue  = SelectService{ProjectName: "prod", ServiceName: "web-blue"}
+	green = SelectService{ProjectName: "prod", ServiceName: "web-green"}
+)
+
+func blueGreenPanel(stats DockerTaskStats) *recordingPanel {
+	live := imageService("ghcr.io/acme/web:1", "A=1")
+	live.SelectService = blue
+	live.Ports = []PortParams{{Protocol: "tcp", Published: 8080, Target: 80}}
+	return newRecordingPanel(map[string]any{
+		"projects.inspectProject":     ProjectInspect{Services: []Service{{Name: "web-blue", Type: ServiceTypeApp}}},
+		"services.app.inspectService": live,
+		"domains.listDomains": []Domain{
+			NewServiceDomain("prod", "web-blue", "app.example.com", 80),
+			NewServiceDomain("prod", "web-blue", "www.example.com", 80),
+		},
+		"monitor.getDockerTaskStats": stats,
+	})
+}
+
+func countPosts(panel *recordingPanel, proc string) int {
+	n := 0
+	for _, p := range panel.order {
+		if p == proc {
+			n++
+		}
+	}
+	return n
+}
+
+func TestServicesBlueGreen(t *testing.T) {
+	panel := blueGreenPanel(DockerTaskStats{"prod_web-green": {Actual: 2, Desired: 2}})
+	client := setupTestClient(t, panel.handler(t))
+
+	env := "A=2"
+	var verified SelectService
+	result, err := client.Services.BlueGreen(context.Background(), blue, green, BlueGreenOptions{
+		Source:       &ServiceSource{Type: "image", DockerImageParams: DockerImageParams{Image: "ghcr.io/acme/web:2"}},
+		Env:          &env,
+		PollInterval: time.Millisecond,
+		Verify: func(ctx context.Context, standby SelectService) error {
+			verified = standby
+			return nil
+		},
+		StopPrevious: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ServiceTypeApp, result.Type)
+	assert.True(t, result.CreatedStandby)
+	assert.Equal(t, green, result.Live)
+	assert.Equal(t, blue, result.Idle)
+	assert.Equal(t, green, verified)
+	require.Len(t, result.Domains, 2)
+
+	var created CreateServiceParams
+	panel.body(t, "services.app.createService", &created)
+	assert.Equal(t, green, created.SelectService)
+	assert.NotContains(t, panel.order, "services.app.updatePorts", "the standby does not publish the live service's ports")
+
+	var image UpdateImage
+	panel.body(t, "services.app.updateSourceImage", &image)
+	assert.Equal(t, "ghcr.io/acme/web:2", image.Image)
+	assert.Equal(t, "web-green", image.ServiceName)
+
+	assert.Equal(t, 2, countPosts(panel, "domains.updateDomain"))
+	var domain Domain
+	panel.body(t, "domains.updateDomain", &domain)
+	assert.Equal(t, "www.example.com", domain.Host)
+	assert.Equal(t, "web-green", domain.ServiceDestination.ServiceName)
+
+	var stopped SelectService
+	panel.body(t, "services.app.stopService", &stopped)
+	assert.Equal(t, blue, stopped)
+	assert.Equal(t, "services.app.stopService", panel.order[len(panel.order)-1])
+}
+
+func TestServicesBlueGreen_VerifyFails(t *testing.T) {
+	panel := blueGreenPanel(DockerTaskStats{"prod_web-green": {Actual: 1, Desired: 1}})
+	client := setupTestClient(t, panel.handler(t))
+
+	_, err := client.Services.BlueGreen(context.Background(), blue, green, BlueGreenOptions{
+		Type:         ServiceTypeApp,
+		PollInterval: time.Millisecond,
+		Verify:       func(context.Context, SelectService) error { return errors.New("smoke test failed") },
+		StopPrevious: true,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "smoke test failed")
+
+	assert.Equal(t, 4, countPosts(panel, "domains.updateDomain"), "both domains are switched and switched back")
+	var domain Domain
+	panel.body(t, "domains.updateDomain", &domain)
+	assert.Equal(t, "web-blue", domain.ServiceDestination.ServiceName)
+	assert.NotContains(t, panel.order, "services.app.stopService")
+
+	var destroyed SelectService
+	panel.body(t, "services.app.destroyService", &destroyed)
+	assert.Equal(t, green, destroyed, "the standby created by the run is removed")
+}
+
+func TestServicesBlueGreen_CanceledDuringVerify(t *testing.T) {
+	panel := blueGreenPanel(DockerTaskStats{"prod_web-green": {Actual: 1, Desired: 1}})
+	client := setupTestClient(t, panel.handler(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := client.Services.BlueGreen(ctx, blue, green, BlueGreenOptions{
+		Type:         ServiceTypeApp,
+		PollInterval: time.Millisecond,
+		Verify: func(ctx context.Context, _ SelectService) error {
+			cancel()
+			return ctx.Err()
+		},
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 4, countPosts(panel, "domains.updateDomain"), "domains are switched back after cancellation")
+	assert.Contains(t, panel.order, "services.app.destroyService")
+}
+
+func TestServicesBlueGreen_UpdateFails(t *testing.T) {
+	panel := blueGreenPanel(DockerTaskStats{"prod_web-green": {Actual: 1, Desired: 1}})
+	panel.failPost = "domains.updateDomain"
+	client := setupTestClient(t, panel.handler(t))
+
+	_, err := client.Services.BlueGreen(context.Background(), blue, green, BlueGreenOptions{Type: ServiceTypeApp, PollInterval: time.Millisecond})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "switch domains: domain app.example.com")
+	assert.Equal(t, 1, countPosts(panel, "domains.updateDomain"), "nothing was switched, so nothing is switched back")
+}
+
+func TestServicesBlueGreen_Unhealthy(t *testing.T) {
+	panel := blueGreenPanel(DockerTaskStats{"prod_web-green": {Actual: 0, Desired: 1}})
+	client := setupTestClient(t, panel.handler(t))
+
+	_, err := client.Services.BlueGreen(context.Background(), blue, green, BlueGreenOptions{
+		Type:          ServiceTypeApp,
+		HealthTimeout: 20 * time.Millisecond,
+		PollInterval:  time.Millisecond,
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "wait healthy")
+	assert.NotContains(t, panel.order, "domains.updateDomain")
+	assert.Contains(t, panel.order, "services.app.destroyService")
+}
+
+func TestServicesBlueGreen_ExistingStandbyKept(t *testing.T) {
+	panel := blueGreenPanel(DockerTaskStats{"prod_web-green": {Actual: 0, Desired: 1}})
+	panel.gets["projects.inspectProject"] = ProjectInspect{Services: []Service{
+		{Name: "web-blue", Type: ServiceTypeApp},
+		{Name: "web-green", Type: ServiceTypeApp},
+	}}
+	client := setupTestClient(t, panel.handler(t))
+
+	_, err := client.Services.BlueGreen(context.Background(), blue, green, BlueGreenOptions{
+		HealthTimeout: 20 * time.Millisecond,
+		PollInterval:  time.Millisecond,
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotContains(t, panel.order, "services.app.destroyService")
+}
+
+func TestServicesBlueGreen_SkipsNonServiceDomains(t *testing.T) {
+	panel := blueGreenPanel(DockerTaskStats{"prod_web-green": {Actual: 1, Desired: 1}})
+	redirect := Domain{ID: "redirect", Host: "old.example.com", Path: "/", DestinationType: "url"}
+	panel.gets["domains.listDomains"] = []Domain{NewServiceDomain("prod", "web-blue", "app.example.com", 80), redirect}
+	client := setupTestClient(t, panel.handler(t))
+
+	result, err := client.Services.BlueGreen(context.Background(), blue, green, BlueGreenOptions{Type: ServiceTypeApp, PollInterval: time.Millisecond})
+	require.NoError(t, err)
+	assert.Len(t, result.Domains, 1)
+	assert.Equal(t, []Domain{redirect}, result.SkippedDomains)
+	assert.Equal(t, 1, countPosts(panel, "domains.updateDomain"))
+}
+
+func TestServicesBlueGreen_Checks(t *testing.T) {
+	panel := blueGreenPanel(nil)
+	panel.gets["domains.listDomains"] = []Domain{}
+	client := setupTestClient(t, panel.handler(t))
+
+	_, err := client.Services.BlueGreen(context.Background(), blue, blue, BlueGreenOptions{Type: ServiceTypeApp})
+	assert.ErrorContains(t, err, "live and standby are the same service")
+
+	_, err = client.Services.BlueGreen(context.Background(), blue, green, BlueGreenOptions{Type: ServiceTypeApp})
+	assert.ErrorContains(t, err, "live service has no domains to switch")
+
+	panel.gets["domains.listDomains"] = []Domain{NewServiceDomain("prod", "web-blue", "app.example.com", 80)}
+	panel.gets["projects.inspectProject"] = ProjectInspect{Services: []Service{
+		{Name: "web-blue", Type: ServiceTypeApp},
+		{Name: "web-green", Type: ServiceTypeCompose},
+	}}
+	_, err = client.Services.BlueGreen(context.Background(), blue, green, BlueGreenOptions{Type: ServiceTypeApp})
+	assert.ErrorContains(t, err, `standby has type "compose", not "app"`)
+	assert.Empty(t, panel.order)
+}
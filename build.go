@@ -0,0 +1,77 @@
+package easypanel
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// nixpacksVersion matches a Nixpacks release such as "1.21.0" or "v1.21.0".
+var nixpacksVersion = regexp.MustCompile(`^v?\d+\.\d+\.\d+$`)
+
+// NixpacksBuild returns a Nixpacks build configuration. An empty version uses
+// the panel's default Nixpacks release. Commands left empty are detected by
+// Nixpacks from the source.
+func NixpacksBuild(version, installCmd, buildCmd, startCmd string) BuildConfig {
+	return BuildConfig{
+		Type:            BuildTypeNixpacks,
+		NixpacksVersion: version,
+		InstallCommand:  installCmd,
+		BuildCommand:    buildCmd,
+		StartCommand:    startCmd,
+	}
+}
+
+// BuildpacksBuild returns a Cloud Native Buildpacks build configuration using
+// the given builder image, for example "heroku/builder:24". An empty builder
+// uses the panel's default.
+func BuildpacksBuild(builder string) BuildConfig {
+	return BuildConfig{Type: BuildTypeHerokuBuildpacks, BuildpacksBuilder: builder}
+}
+
+// DockerfileBuild returns a Dockerfile build configuration. The path is
+// relative to the root of the source; empty means "Dockerfile".
+func DockerfileBuild(dockerfilePath string) BuildConfig {
+	return BuildConfig{Type: BuildTypeDockerfile, DockerfilePath: dockerfilePath}
+}
+
+// Validate checks that the build type is known and that only the options of
+// that type are set.
+func (b BuildConfig) Validate() error {
+	var errs []error
+	nixpacksSet := b.NixpacksVersion != "" || b.InstallCommand != "" || b.BuildCommand != "" || b.StartCommand != ""
+
+	switch b.Type {
+	case BuildTypeNixpacks:
+		if b.NixpacksVersion != "" && !nixpacksVersion.MatchString(b.NixpacksVersion) {
+			errs = append(errs, fmt.Errorf("easypanel: invalid nixpacks version %q", b.NixpacksVersion))
+		}
+	case BuildTypeHerokuBuildpacks:
+		if strings.ContainsAny(b.BuildpacksBuilder, " \t\n") {
+			errs = append(errs, fmt.Errorf("easypanel: invalid buildpacks builder %q", b.BuildpacksBuilder))
+		}
+	case BuildTypeDockerfile:
+		if p := b.DockerfilePath; p != "" {
+			clean := path.Clean(p)
+			if path.IsAbs(p) || clean == ".." || strings.HasPrefix(clean, "../") || strings.HasSuffix(p, "/") {
+				errs = append(errs, fmt.Errorf("easypanel: dockerfile path %q must be a file path relative to the source root", p))
+			}
+		}
+	case BuildTypeNone:
+	default:
+		return fmt.Errorf("easypanel: unknown build type %q", b.Type)
+	}
+
+	if nixpacksSet && b.Type != BuildTypeNixpacks {
+		errs = append(errs, fmt.Errorf("easypanel: nixpacks options set on %q build", b.Type))
+	}
+	if b.BuildpacksBuilder != "" && b.Type != BuildTypeHerokuBuildpacks {
+		errs = append(errs, fmt.Errorf("easypanel: buildpacks builder set on %q build", b.Type))
+	}
+	if b.DockerfilePath != "" && b.Type != BuildTypeDockerfile {
+		errs = append(errs, fmt.Errorf("easypanel: dockerfile path set on %q build", b.Type))
+	}
+	return errors.Join(errs...)
+}
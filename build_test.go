@@ -0,0 +1,130 @@
+package easypanel
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   BuildConfig
+		wantErr string
+	}{
+		{name: "nixpacks", build: NixpacksBuild("1.21.0", "npm ci", "npm run build", "npm start")},
+		{name: "nixpacks default version", build: NixpacksBuild("", "", "", "")},
+		{name: "buildpacks", build: BuildpacksBuild("heroku/builder:24")},
+		{name: "dockerfile", build: DockerfileBuild("docker/Dockerfile.prod")},
+		{name: "dockerfile default path", build: DockerfileBuild("")},
+		{name: "none", build: BuildConfig{Type: BuildTypeNone}},
+		{name: "unknown type", build: BuildConfig{Type: "docker"}, wantErr: `unknown build type "docker"`},
+		{name: "empty type", build: BuildConfig{}, wantErr: `unknown build type ""`},
+		{name: "bad nixpacks version", build: NixpacksBuild("latest", "", "", ""), wantErr: "invalid nixpacks version"},
+		{name: "bad builder", build: BuildpacksBuild("heroku builder"), wantErr: "invalid buildpacks builder"},
+		{name: "absolute dockerfile", build: DockerfileBuild("/Dockerfile"), wantErr: "relative to the source root"},
+		{name: "escaping dockerfile", build: DockerfileBuild("../Dockerfile"), wantErr: "relative to the source root"},
+		{name: "dockerfile directory", build: DockerfileBuild("docker/"), wantErr: "relative to the source root"},
+		{
+			name:    "nixpacks options on dockerfile",
+			build:   BuildConfig{Type: BuildTypeDockerfile, StartCommand: "npm start"},
+			wantErr: `nixpacks options set on "dockerfile" build`,
+		},
+		{
+			name:    "dockerfile path on nixpacks",
+			build:   BuildConfig{Type: BuildTypeNixpacks, DockerfilePath: "Dockerfile"},
+			wantErr: `dockerfile path set on "nixpacks" build`,
+		},
+		{
+			name:    "builder on none",
+			build:   BuildConfig{Type: BuildTypeNone, BuildpacksBuilder: "heroku/builder:24"},
+			wantErr: `buildpacks builder set on "none" build`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.build.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestServicesUpdateBuild(t *testing.T) {
+	build := NixpacksBuild("1.21.0", "", "", "node server.js")
+	params := UpdateBuildParams{
+		SelectService: SelectService{ProjectName: "proj", ServiceName: "svc"},
+		Build:         &build,
+	}
+
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/trpc/services.app.updateBuild", r.URL.Path)
+
+		raw, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var envelope struct {
+			JSON map[string]any `json:"json"`
+		}
+		require.NoError(t, json.Unmarshal(raw, &envelope))
+		assert.Equal(t, map[string]any{
+			"type":            "nixpacks",
+			"nixpacksVersion": "1.21.0",
+			"startCommand":    "node server.js",
+		}, envelope.JSON["build"])
+		assert.NotContains(t, envelope.JSON, "type")
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	err := client.Services.UpdateBuild(context.Background(), ServiceTypeApp, params)
+	require.NoError(t, err)
+}
+
+func TestServicesUpdateBuild_ValidatesLocally(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("no request expected")
+	})
+
+	err := client.Services.UpdateBuild(context.Background(), ServiceTypeApp, UpdateBuildParams{
+		SelectService: SelectService{ProjectName: "proj", ServiceName: "svc"},
+		Build:         &BuildConfig{Type: BuildTypeNone, DockerfilePath: "Dockerfile"},
+	})
+	assert.ErrorContains(t, err, "dockerfile path set")
+}
+
+func TestServicesUpdateBuild_BuildType(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		decodeTRPCBody(t, r, &body)
+		assert.Equal(t, map[string]any{"projectName": "proj", "serviceName": "svc", "type": "dockerfile"}, body)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	err := client.Services.UpdateBuild(context.Background(), ServiceTypeApp, UpdateBuildParams{
+		SelectService: SelectService{ProjectName: "proj", ServiceName: "svc"},
+		BuildType:     "dockerfile",
+	})
+	require.NoError(t, err)
+}
+
+func TestServicesInspect_Build(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.WriteString(w, `{"result":{"data":{"json":{"projectName":"proj","serviceName":"svc","type":"app",`+
+			`"build":{"type":"dockerfile","file":"Dockerfile.prod"}}}}}`)
+		require.NoError(t, err)
+	})
+
+	resp, err := client.Services.Inspect(context.Background(), ServiceTypeApp, SelectService{ProjectName: "proj", ServiceName: "svc"})
+	require.NoError(t, err)
+	assert.Equal(t, &BuildConfig{Type: BuildTypeDockerfile, DockerfilePath: "Dockerfile.prod"}, resp.Result.Data.JSON.Build)
+}
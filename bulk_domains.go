@@ -0,0 +1,119 @@
+package easypanel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DomainChange describes an edit applied to many domains at once. Zero-valued
+// fields leave the corresponding domain attribute untouched.
+type DomainChange struct {
+	// HostPattern selects domains by host. It is either an exact host or contains
+	// a single "*" matching any text, e.g. "*.old.com". Empty matches every domain.
+	HostPattern string
+	// HostReplacement is the new host. A "*" in it is replaced by the text the
+	// pattern's "*" matched, so "*.old.com" → "*.new.com" keeps subdomains.
+	HostReplacement     string
+	HTTPS               *bool
+	CertificateResolver *CertificateResolver
+}
+
+// BulkDomainResult is the outcome of a DomainChange for one domain.
+type BulkDomainResult struct {
+	Before  Domain
+	After   Domain
+	Changed bool  // After differs from Before
+	Err     error // Validation or update error, if any
+}
+
+// ListAll returns the domains of every service in every project.
+func (s *DomainsService) ListAll(ctx context.Context) ([]Domain, error) {
+	var all RestResponse[ProjectsWithServices]
+	if err := s.client.get(ctx, routeListProjectsAndServices, nil, &all); err != nil {
+		return nil, err
+	}
+	var domains []Domain
+	for _, svc := range all.Result.Data.JSON.Services {
+		serviceName := svc.name()
+		resp, err := s.List(ctx, ListDomainsParams{ProjectName: svc.ProjectName, ServiceName: serviceName})
+		if err != nil {
+			return nil, fmt.Errorf("easypanel: list domains of %s/%s: %w", svc.ProjectName, serviceName, err)
+		}
+		domains = append(domains, resp.Result.Data.JSON...)
+	}
+	return domains, nil
+}
+
+// BulkUpdate applies change to every domain it matches and updates the changed
+// ones through Update. With dryRun set, nothing is sent and the results show
+// what would change. Per-domain failures are recorded in the results; the
+// returned error is only set for an invalid change.
+func (s *DomainsService) BulkUpdate(ctx context.Context, domains []Domain, change DomainChange, dryRun bool) ([]BulkDomainResult, error) {
+	if strings.Count(change.HostPattern, "*") > 1 || strings.Count(change.HostReplacement, "*") > 1 {
+		return nil, fmt.Errorf("easypanel: host pattern and replacement may contain at most one \"*\"")
+	}
+	if strings.Contains(change.HostReplacement, "*") && !strings.Contains(change.HostPattern, "*") {
+		return nil, fmt.Errorf("easypanel: host replacement %q uses \"*\" but pattern %q does not", change.HostReplacement, change.HostPattern)
+	}
+
+	var results []BulkDomainResult
+	for _, d := range domains {
+		after, ok := change.apply(d)
+		if !ok {
+			continue
+		}
+		r := BulkDomainResult{Before: d, After: after, Changed: !domainsEqual(d, after)}
+		if r.Changed {
+			if err := after.Validate(); err != nil {
+				r.Err = err
+			} else if !dryRun {
+				r.Err = s.Update(ctx, after)
+			}
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// apply returns d with the change applied, or false if d does not match the pattern.
+func (c DomainChange) apply(d Domain) (Domain, bool) {
+	if c.HostPattern != "" {
+		match, ok := matchHostPattern(c.HostPattern, d.Host)
+		if !ok {
+			return d, false
+		}
+		if c.HostReplacement != "" {
+			d.Host = strings.Replace(c.HostReplacement, "*", match, 1)
+		}
+	}
+	if c.HTTPS != nil {
+		d.HTTPS = *c.HTTPS
+		if !d.HTTPS {
+			d.CertificateResolver = CertificateResolverNone
+		}
+	}
+	if c.CertificateResolver != nil && d.HTTPS {
+		d.CertificateResolver = *c.CertificateResolver
+	}
+	return d, true
+}
+
+// matchHostPattern matches host against a pattern with at most one "*" and
+// returns the text matched by the "*". Matching is case-insensitive.
+func matchHostPattern(pattern, host string) (string, bool) {
+	pattern, host = strings.ToLower(pattern), strings.ToLower(host)
+	prefix, suffix, wild := strings.Cut(pattern, "*")
+	if !wild {
+		return "", host == pattern
+	}
+	if len(host) < len(prefix)+len(suffix) || !strings.HasPrefix(host, prefix) || !strings.HasSuffix(host, suffix) {
+		return "", false
+	}
+	return host[len(prefix) : len(host)-len(suffix)], true
+}
+
+// domainsEqual compares the fields a DomainChange can modify.
+func domainsEqual(a, b Domain) bool {
+	return a.Host == b.Host && a.HTTPS == b.HTTPS && a.CertificateResolver == b.CertificateResolver
+}
@@ -0,0 +1,140 @@
+package easypanel
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchHostPattern(t *testing.T) {
+	tests := []struct {
+		pattern, host, match string
+		ok                   bool
+	}{
+		{"*.old.com", "app.old.com", "app", true},
+		{"*.old.com", "a.b.OLD.com", "a.b", true},
+		{"*.old.com", "old.com", "", false},
+		{"*.old.com", "app.older.com", "", false},
+		{"api.old.com", "api.old.com", "", true},
+		{"api.old.com", "www.old.com", "", false},
+		{"app.*", "app.old.com", "old.com", true},
+	}
+	for _, tt := range tests {
+		match, ok := matchHostPattern(tt.pattern, tt.host)
+		assert.Equal(t, tt.ok, ok, "%s ~ %s", tt.pattern, tt.host)
+		assert.Equal(t, tt.match, match, "%s ~ %s", tt.pattern, tt.host)
+	}
+}
+
+func TestDomainsListAll(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/trpc/projects.listProjectsAndServices":
+			writeJSON(t, w, newRestResponse(ProjectsWithServices{
+				Projects: []ProjectInfo{{Name: "a"}, {Name: "b"}},
+				Services: []Service{
+					{SelectService: SelectService{ProjectName: "a", ServiceName: "web"}},
+					{SelectService: SelectService{ProjectName: "b", ServiceName: "api"}},
+				},
+			}))
+		case "/api/trpc/domains.listDomains":
+			var input ListDomainsParams
+			decodeTRPCQuery(t, r, &input)
+			writeJSON(t, w, newRestResponse([]Domain{
+				NewServiceDomain(input.ProjectName, input.ServiceName, input.ServiceName+".old.com", 80),
+			}))
+		default:
+			t.Fatalf("unexpected request %s", r.URL.Path)
+		}
+	})
+
+	domains, err := client.Domains.ListAll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, domains, 2)
+	assert.Equal(t, "web.old.com", domains[0].Host)
+	assert.Equal(t, "api.old.com", domains[1].Host)
+}
+
+func TestDomainsBulkUpdate(t *testing.T) {
+	domains := []Domain{
+		NewServiceDomain("a", "web", "web.old.com", 80),
+		NewServiceDomain("b", "api", "api.v2.old.com", 80),
+		NewServiceDomain("b", "api", "api.other.com", 80),
+	}
+
+	var updated []Domain
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/trpc/domains.updateDomain", r.URL.Path)
+		var body Domain
+		decodeTRPCBody(t, r, &body)
+		updated = append(updated, body)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	change := DomainChange{HostPattern: "*.old.com", HostReplacement: "*.new.com"}
+
+	preview, err := client.Domains.BulkUpdate(context.Background(), domains, change, true)
+	require.NoError(t, err)
+	require.Len(t, preview, 2)
+	assert.Empty(t, updated, "dry run must not update")
+	assert.Equal(t, "web.new.com", preview[0].After.Host)
+	assert.Equal(t, "api.v2.new.com", preview[1].After.Host)
+
+	results, err := client.Domains.BulkUpdate(context.Background(), domains, change, false)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Len(t, updated, 2)
+	assert.Equal(t, domains[0].ID, updated[0].ID)
+	assert.Equal(t, "web.new.com", updated[0].Host)
+	for _, r := range results {
+		assert.True(t, r.Changed)
+		assert.NoError(t, r.Err)
+	}
+}
+
+func TestDomainsBulkUpdate_HTTPSAndResolver(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("no request expected")
+	})
+
+	secure := NewServiceDomain("a", "web", "web.example.com", 80)
+	insecure := NewServiceDomain("a", "web", "plain.example.com", 80)
+	insecure.HTTPS = false
+	insecure.CertificateResolver = CertificateResolverNone
+
+	off := false
+	results, err := client.Domains.BulkUpdate(context.Background(), []Domain{secure, insecure}, DomainChange{HTTPS: &off}, true)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Changed)
+	assert.False(t, results[0].After.HTTPS)
+	assert.Equal(t, CertificateResolverNone, results[0].After.CertificateResolver)
+	assert.False(t, results[1].Changed)
+
+	resolver := CertificateResolver("cloudflare")
+	results, err = client.Domains.BulkUpdate(context.Background(), []Domain{secure, insecure}, DomainChange{CertificateResolver: &resolver}, true)
+	require.NoError(t, err)
+	assert.Equal(t, resolver, results[0].After.CertificateResolver)
+	assert.False(t, results[1].Changed, "resolver is not applied to HTTP domains")
+}
+
+func TestDomainsBulkUpdate_InvalidChange(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("no request expected")
+	})
+
+	_, err := client.Domains.BulkUpdate(context.Background(), nil, DomainChange{HostPattern: "*.*.com"}, true)
+	assert.ErrorContains(t, err, "at most one")
+
+	_, err = client.Domains.BulkUpdate(context.Background(), nil, DomainChange{HostPattern: "a.com", HostReplacement: "*.b.com"}, true)
+	assert.ErrorContains(t, err, "does not")
+
+	results, err := client.Domains.BulkUpdate(context.Background(), []Domain{NewServiceDomain("a", "web", "a.com", 80)},
+		DomainChange{HostPattern: "a.com", HostReplacement: "bad_host.com"}, false)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.ErrorContains(t, results[0].Err, "invalid character '_'")
+}
@@ -0,0 +1,118 @@
+package easypanel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrUnsupported is returned when the target panel does not provide the
+// procedure an operation needs. Use errors.Is to check for it.
+var ErrUnsupported = errors.New("easypanel: operation not supported by this panel")
+
+// Capabilities describes which groups of procedures a panel provides.
+type Capabilities struct {
+	Domains       bool // domains.* procedures
+	Actions       bool // actions.* procedures
+	Backups       bool // backups.* destination procedures
+	VolumeBackups bool // volumeBackups.* procedures
+	LegacyDomains bool // services.{type}.updateDomains; removed once domains.* exists
+}
+
+// ServerInfo describes the panel the client is connected to.
+type ServerInfo struct {
+	Version      string // Empty if the panel does not report its version
+	Capabilities Capabilities
+}
+
+// capabilityProbes maps each capability to a read-only procedure whose presence
+// indicates support.
+var capabilityProbes = []struct {
+	route string
+	set   func(*Capabilities)
+}{
+	{routeListDomains, func(c *Capabilities) { c.Domains = true }},
+	{routeListActions, func(c *Capabilities) { c.Actions = true }},
+	{routeListBackupDestinations, func(c *Capabilities) { c.Backups = true }},
+	{routeListVolumeBackupSchedules, func(c *Capabilities) { c.VolumeBackups = true }},
+}
+
+// serverInfoCache holds the result of the first successful ServerInfo probe.
+// It is read without locking, so probes never block other requests.
+type serverInfoCache struct {
+	info atomic.Pointer[ServerInfo]
+}
+
+// ServerInfo detects the panel version and the procedures it supports. The
+// result of the first call that completes is cached and returned by later
+// calls. A probe failing with anything other than an unknown-procedure or
+// input validation error, such as a 5xx or 429, fails the call without
+// caching anything. Once detected, operations that need a missing capability
+// fail fast with ErrUnsupported instead of making a request. Concurrent first
+// calls may each probe the panel; the first result stored wins.
+func (c *Client) ServerInfo(ctx context.Context) (ServerInfo, error) {
+	cache := c.client.serverInfo
+	if info := cache.info.Load(); info != nil {
+		return *info, nil
+	}
+
+	var info ServerInfo
+	var version RestResponse[string]
+	if err := c.client.get(ctx, routeGetVersion, nil, &version); err == nil {
+		info.Version = version.Result.Data.JSON
+	} else if !errors.Is(err, ErrUnsupported) {
+		return ServerInfo{}, err
+	}
+
+	for _, p := range capabilityProbes {
+		err := c.client.get(ctx, p.route, nil, nil)
+		if errors.Is(err, ErrUnsupported) {
+			continue
+		}
+		var apiErr *Error
+		if err != nil && !(errors.As(err, &apiErr) && isInputError(apiErr)) {
+			// Transport failure, server error or rate limit: we learned nothing
+			// about this procedure, so nothing is cached and the next call
+			// probes again.
+			return ServerInfo{}, err
+		}
+		// Success or an input validation error means the procedure exists.
+		p.set(&info.Capabilities)
+	}
+	info.Capabilities.LegacyDomains = !info.Capabilities.Domains
+
+	cache.info.CompareAndSwap(nil, &info)
+	return *cache.info.Load(), nil
+}
+
+// require returns an ErrUnsupported error when capabilities have already been
+// detected and the capability needed for route is missing. Before detection it
+// allows every operation, leaving the panel to reject unknown procedures.
+func (c *httpClient) require(route string, has func(Capabilities) bool) error {
+	info := c.serverInfo.info.Load()
+	if info == nil || has(info.Capabilities) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupported, strings.TrimPrefix(route, "/api/trpc/"))
+}
+
+func hasDomains(c Capabilities) bool       { return c.Domains }
+func hasActions(c Capabilities) bool       { return c.Actions }
+func hasBackups(c Capabilities) bool       { return c.Backups }
+func hasVolumeBackups(c Capabilities) bool { return c.VolumeBackups }
+func hasLegacyDomains(c Capabilities) bool { return c.LegacyDomains }
+
+// isInputError reports whether an API error is a procedure rejecting its
+// input, which shows the procedure exists.
+func isInputError(e *Error) bool {
+	return e.StatusCode == 400 || e.StatusCode == 422
+}
+
+// isUnknownProcedure reports whether an API error is tRPC's response for a
+// procedure that does not exist, as opposed to a NOT_FOUND raised by the
+// procedure itself.
+func isUnknownProcedure(e *Error) bool {
+	return e.StatusCode == 404 && strings.Contains(e.ErrorMessage, "procedure on path")
+}
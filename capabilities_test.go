@@ -0,0 +1,169 @@
+package easypanel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeUnknownProcedure mimics tRPC's response for a procedure that does not exist.
+func writeUnknownProcedure(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/trpc/")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	fmt.Fprintf(w, `{"error":{"json":{"message":"No \"query\"-procedure on path \"%s\"","data":{"code":"NOT_FOUND","httpStatus":404}}}}`, path)
+}
+
+func TestServerInfo(t *testing.T) {
+	requests := 0
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, http.MethodGet, r.Method)
+		switch r.URL.Path {
+		case "/api/trpc/settings.getVersion":
+			writeJSON(t, w, newRestResponse("1.41.0"))
+		case "/api/trpc/domains.listDomains", "/api/trpc/volumeBackups.listSchedules":
+			writeUnknownProcedure(w, r)
+		case "/api/trpc/actions.listActions":
+			w.WriteHeader(http.StatusBadRequest)
+			writeJSON(t, w, Error{ErrorMessage: "invalid input"})
+		default:
+			writeJSON(t, w, newRestResponse([]any{}))
+		}
+	})
+
+	info, err := client.ServerInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "1.41.0", info.Version)
+	assert.Equal(t, Capabilities{
+		Actions:       true,
+		Backups:       true,
+		LegacyDomains: true,
+	}, info.Capabilities)
+
+	probes := requests
+	again, err := client.ServerInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, info, again)
+	assert.Equal(t, probes, requests, "second call should use the cache")
+
+	_, err = client.Domains.List(context.Background(), ListDomainsParams{ProjectName: "proj", ServiceName: "web"})
+	assert.ErrorIs(t, err, ErrUnsupported)
+	assert.ErrorContains(t, err, "domains.listDomains")
+	assert.Equal(t, probes, requests, "unsupported call should not reach the panel")
+}
+
+func TestServerInfo_NewDomainModel(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/trpc/settings.getVersion" {
+			writeUnknownProcedure(w, r)
+			return
+		}
+		writeJSON(t, w, newRestResponse([]any{}))
+	})
+
+	info, err := client.ServerInfo(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, info.Version)
+	assert.True(t, info.Capabilities.Domains)
+	assert.False(t, info.Capabilities.LegacyDomains)
+
+	err = client.Services.UpdateDomains(context.Background(), ServiceTypeApp, UpdateDomainsParams{})
+	assert.ErrorIs(t, err, ErrUnsupported)
+	assert.ErrorContains(t, err, "services.app.updateDomains")
+}
+
+func TestServerInfo_ProbeDoesNotBlockRequests(t *testing.T) {
+	probing, release := make(chan struct{}), make(chan struct{})
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/trpc/settings.getVersion" {
+			close(probing)
+			<-release
+			writeJSON(t, w, newRestResponse("1.41.0"))
+			return
+		}
+		writeJSON(t, w, newRestResponse([]any{}))
+	})
+
+	done := make(chan error)
+	go func() {
+		_, err := client.ServerInfo(context.Background())
+		done <- err
+	}()
+	<-probing
+
+	_, err := client.Domains.List(context.Background(), ListDomainsParams{ProjectName: "proj", ServiceName: "web"})
+	assert.NoError(t, err, "requests made while probing are not held back")
+	close(release)
+	require.NoError(t, <-done)
+}
+
+func TestServerInfo_ServerErrorNotCached(t *testing.T) {
+	failing := true
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/trpc/actions.listActions" && failing:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			writeJSON(t, w, Error{ErrorMessage: "unavailable"})
+		case r.URL.Path == "/api/trpc/actions.listActions", r.URL.Path == "/api/trpc/settings.getVersion":
+			writeUnknownProcedure(w, r)
+		default:
+			writeJSON(t, w, newRestResponse([]any{}))
+		}
+	})
+
+	_, err := client.ServerInfo(context.Background())
+	require.Error(t, err)
+	_, err = client.Actions.List(context.Background(), ListActionsParams{ProjectName: "proj"})
+	assert.NotErrorIs(t, err, ErrUnsupported, "a failed probe must not be cached")
+
+	failing = false
+	info, err := client.ServerInfo(context.Background())
+	require.NoError(t, err)
+	assert.False(t, info.Capabilities.Actions)
+	assert.True(t, info.Capabilities.Domains)
+}
+
+func TestServerInfo_RateLimitedProbe(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/trpc/settings.getVersion":
+			writeJSON(t, w, newRestResponse("1.41.0"))
+		case "/api/trpc/domains.listDomains":
+			w.WriteHeader(http.StatusTooManyRequests)
+			writeJSON(t, w, Error{ErrorMessage: "slow down"})
+		default:
+			writeJSON(t, w, newRestResponse([]any{}))
+		}
+	})
+
+	_, err := client.ServerInfo(context.Background())
+	assert.ErrorContains(t, err, "slow down")
+}
+
+func TestErrorIsUnsupported(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/trpc/projects.inspectProject" {
+			w.WriteHeader(http.StatusNotFound)
+			writeJSON(t, w, Error{ErrorMessage: "Project not found"})
+			return
+		}
+		writeUnknownProcedure(w, r)
+	})
+
+	_, err := client.Actions.List(context.Background(), ListActionsParams{ProjectName: "proj"})
+	assert.ErrorIs(t, err, ErrUnsupported)
+
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+
+	_, err = client.Projects.Inspect(context.Background(), ProjectQuery{ProjectName: "missing"})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrUnsupported)
+}
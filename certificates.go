@@ -0,0 +1,121 @@
+package easypanel
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Dialer opens network connections. *net.Dialer satisfies it.
+type Dialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// CertificateInfo describes the TLS certificate served for a domain.
+type CertificateInfo struct {
+	DomainID    string
+	Host        string
+	ServerName  string // SNI name used; a random subdomain for wildcard hosts
+	Issuer      string
+	Subject     string
+	DNSNames    []string
+	NotBefore   time.Time
+	NotAfter    time.Time
+	MatchesHost bool  // The certificate is valid for ServerName
+	Err         error // Set if the certificate could not be fetched
+}
+
+// ExpiresWithin reports whether the certificate expires within d of now.
+// Certificates that could not be fetched are treated as expiring.
+func (c CertificateInfo) ExpiresWithin(d time.Duration, now time.Time) bool {
+	return c.Err != nil || c.NotAfter.Before(now.Add(d))
+}
+
+// CertificateReport lists the certificates checked for a project and those
+// that need attention.
+type CertificateReport struct {
+	ProjectName string
+	Checked     []CertificateInfo
+	Expiring    []CertificateInfo // Expiring soon, not matching the host, or unreachable
+}
+
+// Certificate connects to the domain on port 443 and returns the certificate it
+// serves. The chain is not verified against system roots, so certificates that
+// are self-signed or already expired are still reported.
+func (s *DomainsService) Certificate(ctx context.Context, d Domain) (CertificateInfo, error) {
+	info := CertificateInfo{DomainID: d.ID, Host: d.Host, ServerName: d.Host}
+	if !d.HTTPS {
+		return info, fmt.Errorf("easypanel: domain %q does not use HTTPS", d.Host)
+	}
+	if strings.HasPrefix(d.Host, "*.") {
+		info.ServerName = wildcardProbeHost(d.Host)
+	}
+
+	dialer := s.dialer
+	if dialer == nil {
+		dialer = &net.Dialer{Timeout: 10 * time.Second}
+	}
+	raw, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(info.ServerName, "443"))
+	if err != nil {
+		return info, fmt.Errorf("easypanel: dial %s: %w", info.ServerName, err)
+	}
+	conn := tls.Client(raw, &tls.Config{
+		ServerName: info.ServerName,
+		// Inspection only: we want to see whatever certificate is served.
+		InsecureSkipVerify: true,
+	})
+	defer conn.Close()
+	if err := conn.HandshakeContext(ctx); err != nil {
+		return info, fmt.Errorf("easypanel: tls handshake with %s: %w", info.ServerName, err)
+	}
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return info, fmt.Errorf("easypanel: %s presented no certificate", info.ServerName)
+	}
+	leaf := certs[0]
+	info.Issuer = leaf.Issuer.String()
+	info.Subject = leaf.Subject.String()
+	info.DNSNames = leaf.DNSNames
+	info.NotBefore = leaf.NotBefore
+	info.NotAfter = leaf.NotAfter
+	info.MatchesHost = leaf.VerifyHostname(info.ServerName) == nil
+	return info, nil
+}
+
+// ExpiringCertificates inspects the certificate of every HTTPS domain in the
+// project and reports those expiring within the given number of days, those
+// that do not match their host, and those that could not be fetched.
+func (s *DomainsService) ExpiringCertificates(ctx context.Context, projectName string, days int) (CertificateReport, error) {
+	report := CertificateReport{ProjectName: projectName}
+
+	var project RestResponse[ProjectInspect]
+	if err := s.client.get(ctx, routeInspectProject, ProjectQuery{ProjectName: projectName}, &project); err != nil {
+		return report, err
+	}
+
+	within := time.Duration(days) * 24 * time.Hour
+	now := time.Now()
+	for _, svc := range project.Result.Data.JSON.Services {
+		serviceName := svc.name()
+		domains, err := s.List(ctx, ListDomainsParams{ProjectName: projectName, ServiceName: serviceName})
+		if err != nil {
+			return report, err
+		}
+		for _, d := range domains.Result.Data.JSON {
+			if !d.HTTPS {
+				continue
+			}
+			info, err := s.Certificate(ctx, d)
+			info.Err = err
+			report.Checked = append(report.Checked, info)
+			if info.ExpiresWithin(within, now) || !info.MatchesHost {
+				report.Expiring = append(report.Expiring, info)
+			}
+		}
+	}
+	return report, nil
+}
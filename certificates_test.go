@@ -0,0 +1,155 @@
+package easypanel
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// selfSignedCert returns a certificate for names that expires after validFor.
+func selfSignedCert(t *testing.T, validFor time.Duration, names ...string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: names[0]},
+		DNSNames:     names,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validFor),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// listenerDialer connects every dial to a single local address.
+type listenerDialer struct {
+	addr string
+}
+
+func (d listenerDialer) DialContext(ctx context.Context, network, _ string) (net.Conn, error) {
+	var nd net.Dialer
+	return nd.DialContext(ctx, network, d.addr)
+}
+
+// startTLSServer serves the certificate registered for the requested SNI name,
+// falling back to the "*" entry.
+func startTLSServer(t *testing.T, certs map[string]tls.Certificate) string {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if c, ok := certs[hello.ServerName]; ok {
+				return &c, nil
+			}
+			c := certs["*"]
+			return &c, nil
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_ = conn.(*tls.Conn).Handshake()
+				conn.Close()
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestDomainsCertificate(t *testing.T) {
+	addr := startTLSServer(t, map[string]tls.Certificate{
+		"app.example.com": selfSignedCert(t, 90*24*time.Hour, "app.example.com"),
+		"*":               selfSignedCert(t, 90*24*time.Hour, "*.preview.example.com"),
+	})
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("no request expected")
+	})
+	client.Domains.dialer = listenerDialer{addr: addr}
+	ctx := context.Background()
+
+	info, err := client.Domains.Certificate(ctx, NewServiceDomain("proj", "web", "app.example.com", 80))
+	require.NoError(t, err)
+	assert.True(t, info.MatchesHost)
+	assert.Equal(t, []string{"app.example.com"}, info.DNSNames)
+	assert.Equal(t, "CN=app.example.com", info.Issuer)
+	assert.WithinDuration(t, time.Now().Add(90*24*time.Hour), info.NotAfter, time.Minute)
+
+	wildcard := NewServiceDomain("proj", "web", "*.preview.example.com", 80)
+	wildcard.Wildcard = true
+	info, err = client.Domains.Certificate(ctx, wildcard)
+	require.NoError(t, err)
+	assert.True(t, info.MatchesHost)
+	assert.True(t, strings.HasSuffix(info.ServerName, ".preview.example.com"))
+
+	plain := NewServiceDomain("proj", "web", "app.example.com", 80)
+	plain.HTTPS = false
+	_, err = client.Domains.Certificate(ctx, plain)
+	assert.ErrorContains(t, err, "does not use HTTPS")
+}
+
+func TestDomainsExpiringCertificates(t *testing.T) {
+	addr := startTLSServer(t, map[string]tls.Certificate{
+		"fresh.example.com": selfSignedCert(t, 60*24*time.Hour, "fresh.example.com"),
+		"stale.example.com": selfSignedCert(t, 5*24*time.Hour, "stale.example.com"),
+		"*":                 selfSignedCert(t, 60*24*time.Hour, "traefik.default"),
+	})
+
+	fresh := NewServiceDomain("proj", "web", "fresh.example.com", 80)
+	stale := NewServiceDomain("proj", "web", "stale.example.com", 80)
+	wrong := NewServiceDomain("proj", "api", "api.example.com", 80)
+	plain := NewServiceDomain("proj", "api", "plain.example.com", 80)
+	plain.HTTPS = false
+
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/trpc/projects.inspectProject":
+			writeJSON(t, w, newRestResponse(ProjectInspect{
+				Project: ProjectInfo{Name: "proj"},
+				Services: []Service{
+					{SelectService: SelectService{ProjectName: "proj", ServiceName: "web"}},
+					{SelectService: SelectService{ProjectName: "proj"}, Name: "api"},
+				},
+			}))
+		case "/api/trpc/domains.listDomains":
+			var input ListDomainsParams
+			decodeTRPCQuery(t, r, &input)
+			if input.ServiceName == "web" {
+				writeJSON(t, w, newRestResponse([]Domain{fresh, stale}))
+			} else {
+				writeJSON(t, w, newRestResponse([]Domain{wrong, plain}))
+			}
+		default:
+			t.Fatalf("unexpected request %s", r.URL.Path)
+		}
+	})
+	client.Domains.dialer = listenerDialer{addr: addr}
+
+	report, err := client.Domains.ExpiringCertificates(context.Background(), "proj", 30)
+	require.NoError(t, err)
+	require.Len(t, report.Checked, 3)
+	require.Len(t, report.Expiring, 2)
+	assert.Equal(t, "stale.example.com", report.Expiring[0].Host)
+	assert.True(t, report.Expiring[0].MatchesHost)
+	assert.Equal(t, "api.example.com", report.Expiring[1].Host)
+	assert.False(t, report.Expiring[1].MatchesHost)
+}
@@ -15,6 +15,7 @@ type httpClient struct {
 	baseURL    string
 	token      string
 	httpClient *http.Client
+	serverInfo *serverInfoCache
 }
 
 func newHTTPClient(baseURL, token string) *httpClient {
@@ -24,6 +25,7 @@ func newHTTPClient(baseURL, token string) *httpClient {
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		serverInfo: &serverInfoCache{},
 	}
 }
 
@@ -0,0 +1,341 @@
+package easypanel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// secretEnvKey matches environment variable names that usually hold secrets.
+var secretEnvKey = regexp.MustCompile(`(?i)(passw|secret|token|api_?key|private|credential|_key$|^key$|dsn$|database_url)`)
+
+// CloneOptions controls ServicesService.Clone.
+type CloneOptions struct {
+	// Type is the type of the source service. When empty it is looked up in
+	// the source project.
+	Type ServiceType
+	// RewriteDomain returns the host the clone should use for a domain of the
+	// source, or "" to leave that domain out. When nil no domains are cloned,
+	// since two services serving the same host would compete for its traffic.
+	RewriteDomain func(host string) string
+	// RewritePort returns the host port the clone should publish instead of
+	// published, or 0 to leave the port out. When nil ports are copied
+	// unchanged, which fails if the clone runs on the same server.
+	RewritePort func(published int) int
+	// SkipSecrets leaves out values that look like secrets: environment values
+	// whose names suggest credentials, basic auth users, registry passwords and
+	// database passwords. The panel generates new database passwords.
+	SkipSecrets bool
+	// RewriteEnv, when set, edits the environment before it is applied to the
+	// clone, after SkipSecrets redaction.
+	RewriteEnv func(env string) string
+	// Deploy deploys the clone once it is configured.
+	Deploy bool
+}
+
+// CloneResult describes what ServicesService.Clone created.
+type CloneResult struct {
+	Type        ServiceType
+	Service     Service  // The clone as returned by Create
+	Created     bool     // Whether the clone was created, even if a later step failed
+	Domains     []Domain // Domains created for the clone
+	RedactedEnv []string // Environment variables whose values were left empty
+}
+
+// Clone recreates the service from on to, which may be in another project.
+// Source, build, environment, mounts, ports, resources, deploy settings,
+// redirects and basic auth are copied; backup schedules and deploy tokens are
+// not. The target must not exist yet. If a step fails, the partially
+// configured clone is left in place and the error names the step.
+func (s *ServicesService) Clone(ctx context.Context, from, to SelectService, opts CloneOptions) (CloneResult, error) {
+	result := CloneResult{Type: opts.Type}
+	if result.Type == "" {
+		st, err := s.lookupType(ctx, from)
+		if err != nil {
+			return result, err
+		}
+		result.Type = st
+	}
+	st := result.Type
+
+	inspected, err := s.Inspect(ctx, st, SelectService{ProjectName: from.ProjectName, ServiceName: from.ServiceName})
+	if err != nil {
+		return result, fmt.Errorf("easypanel: clone: inspect %s/%s: %w", from.ProjectName, from.ServiceName, err)
+	}
+	src := inspected.Result.Data.JSON
+	target := SelectService{ProjectName: to.ProjectName, ServiceName: to.ServiceName}
+
+	create := CreateServiceParams{SelectService: target}
+	if st.IsDatabase() {
+		create.Image = src.Image
+		if !opts.SkipSecrets {
+			create.Password, create.RootPassword = src.Password, src.RootPassword
+		}
+	}
+	created, err := s.Create(ctx, st, create)
+	if err != nil {
+		return result, fmt.Errorf("easypanel: clone: create %s/%s: %w", to.ProjectName, to.ServiceName, err)
+	}
+	result.Service, result.Created = created.Result.Data.JSON, true
+
+	env := src.Env
+	if opts.SkipSecrets {
+		env, result.RedactedEnv = redactEnv(env)
+	}
+	if opts.RewriteEnv != nil {
+		env = opts.RewriteEnv(env)
+	}
+
+	steps := []struct {
+		name string
+		skip bool
+		run  func() error
+	}{
+		{"source", src.Source == nil, func() error { return s.cloneSource(ctx, st, target, *src.Source, opts.SkipSecrets) }},
+		{"build", src.Build == nil, func() error {
+			return s.UpdateBuild(ctx, st, UpdateBuildParams{SelectService: target, Build: src.Build})
+		}},
+		{"env", env == "", func() error {
+			return s.UpdateEnv(ctx, st, UpdateEnv{SelectService: target, Env: env})
+		}},
+		{"mounts", len(src.Mounts) == 0, func() error {
+			return s.UpdateMounts(ctx, st, MountParams{SelectService: target, Mounts: src.Mounts})
+		}},
+		{"ports", len(src.Ports) == 0, func() error {
+			ports := rewritePorts(src.Ports, opts.RewritePort)
+			if len(ports) == 0 {
+				return nil
+			}
+			return s.UpdatePorts(ctx, st, UpdatePorts{SelectService: target, Ports: ports})
+		}},
+		{"resources", src.Resources == (Resources{}), func() error {
+			return s.UpdateResources(ctx, st, UpdateResources{SelectService: target, Resources: src.Resources})
+		}},
+		{"deploy settings", src.Deploy == nil, func() error {
+			deploy := *src.Deploy
+			deploy.SelectService = target
+			return s.UpdateDeploy(ctx, st, deploy)
+		}},
+		{"redirects", len(src.Redirects) == 0, func() error {
+			return s.UpdateRedirects(ctx, st, UpdateRedirects{SelectService: target, Redirects: src.Redirects})
+		}},
+		{"basic auth", len(src.BasicAuth) == 0 || opts.SkipSecrets, func() error {
+			return s.UpdateBasicAuth(ctx, st, UpdateBasicAuth{SelectService: target, BasicAuth: src.BasicAuth})
+		}},
+		{"domains", opts.RewriteDomain == nil, func() (err error) {
+			result.Domains, err = s.cloneDomains(ctx, st, src, from, target, opts.RewriteDomain)
+			return err
+		}},
+		{"deploy", !opts.Deploy, func() error { return s.Deploy(ctx, st, target) }},
+	}
+	for _, step := range steps {
+		if step.skip {
+			continue
+		}
+		if err := step.run(); err != nil {
+			return result, fmt.Errorf("easypanel: clone %s/%s: %s: %w", to.ProjectName, to.ServiceName, step.name, err)
+		}
+	}
+	return result, nil
+}
+
+// lookupType finds the type of svc among the services of its project.
+func (s *ServicesService) lookupType(ctx context.Context, svc SelectService) (ServiceType, error) {
+	st, found, err := s.findType(ctx, svc)
+	if err == nil && !found {
+		err = fmt.Errorf("easypanel: service %s/%s not found", svc.ProjectName, svc.ServiceName)
+	}
+	return st, err
+}
+
+// findType is like lookupType but reports a missing service with found false
+// rather than an error.
+func (s *ServicesService) findType(ctx context.Context, svc SelectService) (st ServiceType, found bool, err error) {
+	var resp RestResponse[ProjectInspect]
+	if err := s.client.get(ctx, routeInspectProject, ProjectQuery{ProjectName: svc.ProjectName}, &resp); err != nil {
+		return "", false, fmt.Errorf("easypanel: inspect project %q: %w", svc.ProjectName, err)
+	}
+	for _, candidate := range resp.Result.Data.JSON.Services {
+		if candidate.name() == svc.ServiceName {
+			return candidate.Type, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// cloneSource applies src to target using the update call for its source type.
+func (s *ServicesService) cloneSource(ctx context.Context, st ServiceType, target SelectService, src ServiceSource, skipSecrets bool) error {
+	params, err := sourceParams(st, target, src, skipSecrets)
+	if err != nil {
+		return err
+	}
+	return s.applySource(ctx, st, params)
+}
+
+// sourceParams returns the parameters of the update call that sets src on
+// target: an UpdateImage, UpdateGithub, UpdateGit, UpdateDockerfile,
+// UpdateSourceInline or UpdateSourceGitCompose, or nil for an empty source.
+func sourceParams(st ServiceType, target SelectService, src ServiceSource, skipSecrets bool) (any, error) {
+	sourceType := src.Type
+	if sourceType == "" {
+		switch {
+		case src.Image != "":
+			sourceType = "image"
+		case src.Owner != "":
+			sourceType = "github"
+		case src.GitParams.Repo != "":
+			sourceType = "git"
+		case src.Dockerfile != "":
+			sourceType = "dockerfile"
+		}
+	}
+
+	if st == ServiceTypeCompose {
+		if src.ComposeContent != "" {
+			return UpdateSourceInline{
+				ProjectName:    target.ProjectName,
+				ServiceName:    target.ServiceName,
+				ComposeFile:    src.ComposeFile,
+				ComposeContent: src.ComposeContent,
+			}, nil
+		}
+		return UpdateSourceGitCompose{
+			ProjectName: target.ProjectName,
+			ServiceName: target.ServiceName,
+			Repo:        src.GitParams.Repo,
+			Ref:         src.GitParams.Branch,
+			RootPath:    src.GitParams.Path,
+			ComposeFile: src.ComposeFile,
+			AutoDeploy:  src.AutoDeploy,
+		}, nil
+	}
+
+	switch sourceType {
+	case "image":
+		params := UpdateImage{ProjectName: target.ProjectName, ServiceName: target.ServiceName, Image: src.Image, Username: src.Username}
+		if !skipSecrets {
+			params.Password = src.DockerImageParams.Password
+		}
+		return params, nil
+	case "github":
+		return UpdateGithub{SelectService: target, GithubParams: src.GithubParams, AutoDeploy: src.AutoDeploy}, nil
+	case "git":
+		return UpdateGit{SelectService: target, GitParams: src.GitParams, AutoDeploy: src.AutoDeploy}, nil
+	case "dockerfile":
+		return UpdateDockerfile{SelectService: target, Dockerfile: src.Dockerfile}, nil
+	case "":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("easypanel: unknown source type %q", sourceType)
+}
+
+// applySource sends parameters returned by sourceParams.
+func (s *ServicesService) applySource(ctx context.Context, st ServiceType, params any) error {
+	switch p := params.(type) {
+	case UpdateImage:
+		return s.UpdateSourceImage(ctx, st, p)
+	case UpdateGithub:
+		return s.UpdateSourceGithub(ctx, st, p)
+	case UpdateGit:
+		return s.UpdateSourceGit(ctx, st, p)
+	case UpdateDockerfile:
+		return s.UpdateSourceDockerfile(ctx, st, p)
+	case UpdateSourceInline:
+		return s.UpdateSourceInline(ctx, st, p)
+	case UpdateSourceGitCompose:
+		return s.UpdateSourceGitCompose(ctx, st, p)
+	}
+	return nil
+}
+
+// cloneDomains recreates the domains of the source service for target with
+// rewritten hosts. Legacy domains stored on the service are rewritten too,
+// unless the panel no longer has the legacy domain API.
+func (s *ServicesService) cloneDomains(ctx context.Context, st ServiceType, src Service, from, target SelectService, rewrite func(string) string) ([]Domain, error) {
+	if len(src.Domains) > 0 {
+		var legacy []DomainParams
+		for _, d := range src.Domains {
+			if host := rewrite(d.Host); host != "" {
+				d.Host = host
+				legacy = append(legacy, d)
+			}
+		}
+		if len(legacy) > 0 {
+			err := s.UpdateDomains(ctx, st, UpdateDomainsParams{SelectService: target, Domains: legacy})
+			if err != nil && !errors.Is(err, ErrUnsupported) {
+				return nil, err
+			}
+		}
+	}
+
+	domains := s.domains()
+	existing, err := domains.List(ctx, ListDomainsParams{ProjectName: from.ProjectName, ServiceName: from.ServiceName})
+	if errors.Is(err, ErrUnsupported) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var created []Domain
+	for _, d := range existing.Result.Data.JSON {
+		host := rewrite(d.Host)
+		if host == "" {
+			continue
+		}
+		d.ID = newDomainID()
+		d.Host = host
+		if d.ServiceDestination != nil {
+			dest := *d.ServiceDestination
+			dest.ProjectName, dest.ServiceName = target.ProjectName, target.ServiceName
+			d.ServiceDestination = &dest
+		}
+		resp, err := domains.Create(ctx, d)
+		if err != nil {
+			return created, fmt.Errorf("domain %s: %w", host, err)
+		}
+		if resp.Result.Data.JSON.ID != "" {
+			d = resp.Result.Data.JSON
+		}
+		created = append(created, d)
+	}
+	return created, nil
+}
+
+// rewritePorts applies rewrite to the published port of each mapping, dropping
+// mappings rewritten to 0.
+func rewritePorts(ports []PortParams, rewrite func(int) int) []PortParams {
+	if rewrite == nil {
+		return ports
+	}
+	out := make([]PortParams, 0, len(ports))
+	for _, p := range ports {
+		if p.Published = rewrite(p.Published); p.Published != 0 {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// redactEnv empties the values of variables whose names look like secrets and
+// returns the edited environment with the names of the redacted variables.
+func redactEnv(env string) (string, []string) {
+	var redacted []string
+	lines := strings.Split(env, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		name := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(key), "export "))
+		if !ok || value == "" || !secretEnvKey.MatchString(name) {
+			continue
+		}
+		lines[i] = key + "="
+		redacted = append(redacted, name)
+	}
+	return strings.Join(lines, "\n"), redacted
+}
@@ -0,0 +1,265 @@
+package easypanel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPanel answers GET routes from fixtures and records POST bodies by
+// procedure name, e.g. "services.app.updateEnv". POSTs echo their input.
+type recordingPanel struct {
+	mu       sync.Mutex
+	gets     map[string]any
+	posts    map[string]json.RawMessage
+	order    []string
+	failPost string
+}
+
+func newRecordingPanel(gets map[string]any) *recordingPanel {
+	return &recordingPanel{gets: gets, posts: make(map[string]json.RawMessage)}
+}
+
+func (p *recordingPanel) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		proc := strings.TrimPrefix(r.URL.Path, "/api/trpc/")
+		if r.Method == http.MethodGet {
+			data, ok := p.gets[proc]
+			if !ok {
+				t.Errorf("unexpected GET %s", proc)
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			writeJSON(t, w, newRestResponse(data))
+			return
+		}
+		var body json.RawMessage
+		decodeTRPCBody(t, r, &body)
+		p.posts[proc] = body
+		p.order = append(p.order, proc)
+		if proc == p.failPost {
+			w.WriteHeader(http.StatusBadRequest)
+			writeJSON(t, w, Error{ErrorMessage: "rejected"})
+			return
+		}
+		writeJSON(t, w, newRestResponse(body))
+	}
+}
+
+func (p *recordingPanel) body(t *testing.T, proc string, target any) {
+	t.Helper()
+	p.mu.Lock()
+	raw, ok := p.posts[proc]
+	p.mu.Unlock()
+	require.True(t, ok, "no POST to %s", proc)
+	require.NoError(t, json.Unmarshal(raw, target))
+}
+
+func cloneSourceApp() Service {
+	return Service{
+		SelectService: SelectService{ProjectName: "prod", ServiceName: "web"},
+		Type:          ServiceTypeApp,
+		Env:           "NODE_ENV=production\nDB_PASSWORD=hunter2\n# comment\nSTRIPE_API_KEY=sk_live\nEMPTY_TOKEN=",
+		Source: &ServiceSource{
+			Type:         "github",
+			AutoDeploy:   true,
+			GithubParams: GithubParams{Owner: "acme", Repo: "web", Branch: "main", Path: "/"},
+		},
+		Build:     &BuildConfig{Type: BuildTypeDockerfile, DockerfilePath: "Dockerfile"},
+		Mounts:    []MountEntry{{Type: "volume", Name: "data", MountPath: "/data"}},
+		Ports:     []PortParams{{Protocol: "tcp", Published: 8080, Target: 80}, {Protocol: "udp", Published: 9000, Target: 9000}},
+		Resources: Resources{CPULimit: 1, MemoryLimit: 512},
+		Deploy:    &DeployParams{Replicas: 2, Command: []string{"serve"}},
+		Redirects: []RedirectParams{{Enabled: true, Regex: "^/old", Replacement: "/new"}},
+		BasicAuth: []UserParams{{Username: "admin", Password: "secret"}},
+	}
+}
+
+func TestServicesClone(t *testing.T) {
+	panel := newRecordingPanel(map[string]any{
+		"projects.inspectProject":     ProjectInspect{Services: []Service{{Name: "web", Type: ServiceTypeApp}}},
+		"services.app.inspectService": cloneSourceApp(),
+		"domains.listDomains": []Domain{
+			NewServiceDomain("prod", "web", "app.example.com", 80),
+			NewServiceDomain("prod", "web", "internal.example.net", 80),
+		},
+	})
+	client := setupTestClient(t, panel.handler(t))
+
+	result, err := client.Services.Clone(context.Background(),
+		SelectService{ProjectName: "prod", ServiceName: "web"},
+		SelectService{ProjectName: "staging", ServiceName: "web"},
+		CloneOptions{
+			RewriteDomain: func(host string) string {
+				if strings.HasSuffix(host, ".example.com") {
+					return "staging-" + host
+				}
+				return ""
+			},
+			RewritePort: func(published int) int {
+				if published == 9000 {
+					return 0
+				}
+				return published + 10000
+			},
+			Deploy: true,
+		})
+	require.NoError(t, err)
+	assert.Equal(t, ServiceTypeApp, result.Type)
+	assert.Empty(t, result.RedactedEnv)
+
+	target := SelectService{ProjectName: "staging", ServiceName: "web"}
+	var created CreateServiceParams
+	panel.body(t, "services.app.createService", &created)
+	assert.Equal(t, target, created.SelectService)
+
+	var github UpdateGithub
+	panel.body(t, "services.app.updateSourceGithub", &github)
+	assert.Equal(t, UpdateGithub{SelectService: target, GithubParams: GithubParams{Owner: "acme", Repo: "web", Branch: "main", Path: "/"}, AutoDeploy: true}, github)
+
+	var env UpdateEnv
+	panel.body(t, "services.app.updateEnv", &env)
+	assert.Equal(t, cloneSourceApp().Env, env.Env)
+
+	var ports UpdatePorts
+	panel.body(t, "services.app.updatePorts", &ports)
+	assert.Equal(t, []PortParams{{Protocol: "tcp", Published: 18080, Target: 80}}, ports.Ports)
+
+	var deploy DeployParams
+	panel.body(t, "services.app.updateDeploy", &deploy)
+	assert.Equal(t, target, deploy.SelectService)
+	assert.Equal(t, 2, deploy.Replicas)
+
+	var auth UpdateBasicAuth
+	panel.body(t, "services.app.updateBasicAuth", &auth)
+	assert.Equal(t, cloneSourceApp().BasicAuth, auth.BasicAuth)
+
+	var domain Domain
+	panel.body(t, "domains.createDomain", &domain)
+	assert.Equal(t, "staging-app.example.com", domain.Host)
+	assert.Equal(t, "staging", domain.ServiceDestination.ProjectName)
+	require.Len(t, result.Domains, 1)
+	assert.NotEqual(t, domain.ID, "", "the clone gets a fresh domain ID")
+
+	for _, proc := range []string{"services.app.updateBuild", "services.app.updateMounts", "services.app.updateResources", "services.app.updateRedirects"} {
+		assert.Contains(t, panel.order, proc)
+	}
+	assert.Equal(t, "services.app.deployService", panel.order[len(panel.order)-1])
+}
+
+func TestServicesClone_SkipSecrets(t *testing.T) {
+	src := cloneSourceApp()
+	src.Source = &ServiceSource{Type: "image", DockerImageParams: DockerImageParams{Image: "ghcr.io/acme/web:1", Username: "bot", Password: "registry-pass"}}
+	panel := newRecordingPanel(map[string]any{"services.app.inspectService": src})
+	client := setupTestClient(t, panel.handler(t))
+
+	result, err := client.Services.Clone(context.Background(),
+		SelectService{ProjectName: "prod", ServiceName: "web"},
+		SelectService{ProjectName: "prod", ServiceName: "web-copy"},
+		CloneOptions{Type: ServiceTypeApp, SkipSecrets: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DB_PASSWORD", "STRIPE_API_KEY"}, result.RedactedEnv)
+
+	var env UpdateEnv
+	panel.body(t, "services.app.updateEnv", &env)
+	assert.Equal(t, "NODE_ENV=production\nDB_PASSWORD=\n# comment\nSTRIPE_API_KEY=\nEMPTY_TOKEN=", env.Env)
+
+	var image UpdateImage
+	panel.body(t, "services.app.updateSourceImage", &image)
+	assert.Equal(t, "bot", image.Username)
+	assert.Empty(t, image.Password)
+
+	assert.NotContains(t, panel.order, "services.app.updateBasicAuth")
+	assert.NotContains(t, panel.order, "services.app.deployService")
+	assert.NotContains(t, panel.order, "domains.createDomain", "domains are not cloned without RewriteDomain")
+}
+
+func TestServicesClone_Database(t *testing.T) {
+	panel := newRecordingPanel(map[string]any{
+		"services.postgres.inspectService": Service{
+			SelectService: SelectService{ProjectName: "prod", ServiceName: "db", Image: "postgres:16", Password: "pw"},
+			Type:          ServiceTypePostgres,
+		},
+	})
+	client := setupTestClient(t, panel.handler(t))
+
+	_, err := client.Services.Clone(context.Background(),
+		SelectService{ProjectName: "prod", ServiceName: "db"},
+		SelectService{ProjectName: "staging", ServiceName: "db"},
+		CloneOptions{Type: ServiceTypePostgres})
+	require.NoError(t, err)
+
+	var created CreateServiceParams
+	panel.body(t, "services.postgres.createService", &created)
+	assert.Equal(t, SelectService{ProjectName: "staging", ServiceName: "db", Image: "postgres:16", Password: "pw"}, created.SelectService)
+	assert.Equal(t, []string{"services.postgres.createService"}, panel.order)
+}
+
+func TestServicesClone_StepError(t *testing.T) {
+	panel := newRecordingPanel(map[string]any{"services.app.inspectService": cloneSourceApp()})
+	panel.failPost = "services.app.updateMounts"
+	client := setupTestClient(t, panel.handler(t))
+
+	_, err := client.Services.Clone(context.Background(),
+		SelectService{ProjectName: "prod", ServiceName: "web"},
+		SelectService{ProjectName: "prod", ServiceName: "copy"},
+		CloneOptions{Type: ServiceTypeApp})
+	assert.ErrorContains(t, err, "clone prod/copy: mounts: rejected")
+	assert.NotContains(t, panel.order, "services.app.updatePorts")
+}
+
+func TestServicesClone_UnknownService(t *testing.T) {
+	panel := newRecordingPanel(map[string]any{"projects.inspectProject": ProjectInspect{}})
+	client := setupTestClient(t, panel.handler(t))
+
+	_, err := client.Services.Clone(context.Background(),
+		SelectService{ProjectName: "prod", ServiceName: "nope"},
+		SelectService{ProjectName: "prod", ServiceName: "copy"},
+		CloneOptions{})
+	assert.ErrorContains(t, err, "service prod/nope not found")
+	assert.Empty(t, panel.order)
+}
+
+func TestServiceSourceJSON(t *testing.T) {
+	var src ServiceSource
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"git","repo":"https://git.example.com/a.git","branch":"dev","path":"/app","autoDeploy":true}`), &src))
+	assert.Equal(t, GitParams{Repo: "https://git.example.com/a.git", Branch: "dev", Path: "/app"}, src.GitParams)
+	assert.Equal(t, "https://git.example.com/a.git", src.GithubParams.Repo)
+
+	out, err := json.Marshal(ServiceSource{GithubParams: GithubParams{Owner: "acme", Repo: "web", Branch: "main"}})
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `"repo":"web"`)
+	assert.Contains(t, string(out), `"branch":"main"`)
+}
+
+func TestServicesClone_LegacyDomainsUnsupported(t *testing.T) {
+	src := cloneSourceApp()
+	src.Domains = []DomainParams{{Host: "old.example.com", Port: 80}}
+	panel := newRecordingPanel(map[string]any{
+		"services.app.inspectService": src,
+		"domains.listDomains":         []Domain{},
+	})
+	recorded := panel.handler(t)
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/trpc/services.app.updateDomains" {
+			writeUnknownProcedure(w, r)
+			return
+		}
+		recorded(w, r)
+	})
+
+	_, err := client.Services.Clone(context.Background(),
+		SelectService{ProjectName: "prod", ServiceName: "web"},
+		SelectService{ProjectName: "prod", ServiceName: "copy"},
+		CloneOptions{Type: ServiceTypeApp, RewriteDomain: func(host string) string { return "copy-" + host }})
+	require.NoError(t, err)
+	assert.Contains(t, panel.order, "services.app.updateBasicAuth")
+}
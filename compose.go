@@ -0,0 +1,569 @@
+package easypanel
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ComposeSpec is a docker-compose file as deployed by a compose service. Only
+// the parts the SDK validates are modelled; every other key is kept in Extra
+// and written back unchanged by Marshal.
+type ComposeSpec struct {
+	Version  string                        `yaml:"version,omitempty"`
+	Name     string                        `yaml:"name,omitempty"`
+	Services map[string]ComposeServiceSpec `yaml:"services"`
+	Volumes  map[string]any                `yaml:"volumes,omitempty"`
+	Networks map[string]any                `yaml:"networks,omitempty"`
+	Extra    map[string]any                `yaml:",inline"`
+}
+
+// ComposeServiceSpec is one service of a ComposeSpec. Keys such as command,
+// deploy or healthcheck are kept in Extra.
+type ComposeServiceSpec struct {
+	Image       string         `yaml:"image,omitempty"`
+	Environment ComposeEnv     `yaml:"environment,omitempty"`
+	Ports       ComposePorts   `yaml:"ports,omitempty"`
+	Volumes     []ComposeMount `yaml:"volumes,omitempty"`
+	Extra       map[string]any `yaml:",inline"`
+}
+
+// ComposeEnv holds a service's environment. Both the mapping and the
+// "KEY=value" list syntax are accepted. Variables without a value, such as
+// "KEY" in a list or "KEY:" in a mapping, are nil so that compose takes them
+// from the shell; "KEY=" is an empty string.
+type ComposeEnv map[string]*string
+
+// ComposePort is a published port of a compose service. Published is zero
+// when the port is only exposed to other services.
+type ComposePort struct {
+	Target int
+	// TargetEnd is the last container port of a range such as
+	// "9000-9001:9000-9001", and zero otherwise. Host and container ranges
+	// must then have the same size.
+	TargetEnd int
+	Published int
+	// PublishedEnd is the last host port of a host range, as in
+	// "8000-8002:80" or "9000-9001:9000-9001", and zero otherwise.
+	PublishedEnd int
+	Protocol     string // "tcp" (default) or "udp"
+	HostIP       string
+	Mode         string         // "ingress" (default) or "host"
+	Extra        map[string]any // Other long-syntax keys, such as app_protocol
+	// Raw holds the entry as written, a string or a mapping, when it uses
+	// variable interpolation such as "${PORT:-8081}:81". The SDK cannot
+	// resolve variables, so the other fields are zero, Validate skips the port
+	// and Marshal writes Raw back unchanged.
+	Raw any
+}
+
+// ComposePorts is a list of ports. A short-syntax range such as
+// "8000-8002:80-82" is kept as one ComposePort and written back as is.
+type ComposePorts []ComposePort
+
+// ComposeMount is a volume or bind mount of a compose service.
+type ComposeMount struct {
+	Type     string // "volume" or "bind"; inferred from Source when empty
+	Source   string // Volume name or host path; empty for anonymous volumes
+	Target   string
+	ReadOnly bool
+	Extra    map[string]any // Other long-syntax keys, such as volume.nocopy
+}
+
+// unsupportedComposeKeys lists service keys ignored or rejected when a stack is
+// deployed on Docker Swarm, with a hint for the swarm alternative.
+var unsupportedComposeKeys = map[string]string{
+	"build":          "build the image elsewhere and reference it with image",
+	"container_name": "swarm names containers itself",
+	"links":          "services reach each other by name on the stack network",
+	"external_links": "attach an external network instead",
+	"network_mode":   "use networks instead",
+	"devices":        "devices cannot be mapped into swarm services",
+	"cgroup_parent":  "not supported by swarm",
+	"userns_mode":    "not supported by swarm",
+	"security_opt":   "not supported by swarm",
+}
+
+// reservedComposePorts are host ports already used by the panel itself.
+var reservedComposePorts = map[int]string{
+	80:   "the panel's HTTP proxy",
+	443:  "the panel's HTTPS proxy",
+	3000: "the panel UI",
+}
+
+var composeServiceName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// ParseCompose parses docker-compose YAML content. It does not validate the
+// result; call Validate for that.
+func ParseCompose(content string) (*ComposeSpec, error) {
+	var spec ComposeSpec
+	if err := yaml.Unmarshal([]byte(content), &spec); err != nil {
+		return nil, fmt.Errorf("easypanel: parse compose file: %w", err)
+	}
+	return &spec, nil
+}
+
+// Marshal encodes the spec as YAML with two-space indentation.
+func (c *ComposeSpec) Marshal() (string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return "", fmt.Errorf("easypanel: encode compose file: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("easypanel: encode compose file: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// Inline validates and encodes the spec as the parameters of
+// UpdateSourceInline for the given compose service.
+func (c *ComposeSpec) Inline(projectName, serviceName string) (UpdateSourceInline, error) {
+	if err := c.Validate(); err != nil {
+		return UpdateSourceInline{}, err
+	}
+	content, err := c.Marshal()
+	if err != nil {
+		return UpdateSourceInline{}, err
+	}
+	return UpdateSourceInline{
+		ProjectName:    projectName,
+		ServiceName:    serviceName,
+		ComposeFile:    "docker-compose.yml",
+		ComposeContent: content,
+	}, nil
+}
+
+// Validate checks the spec against what the panel can deploy on Docker Swarm:
+// every service needs an image, swarm-incompatible keys such as build are
+// rejected, host ports must not be published twice or collide with the
+// panel's own ports, and named volumes must be declared.
+func (c *ComposeSpec) Validate() error {
+	var errs []error
+	for _, key := range sortedKeys(c.Extra) {
+		switch {
+		case strings.HasPrefix(key, "x-"), key == "configs", key == "secrets":
+		default:
+			errs = append(errs, fmt.Errorf("easypanel: unsupported top-level compose key %q", key))
+		}
+	}
+	if len(c.Services) == 0 {
+		errs = append(errs, fmt.Errorf("easypanel: compose file has no services"))
+	}
+
+	published := make(map[string]string)
+	for _, name := range sortedKeys(c.Services) {
+		svc := c.Services[name]
+		if !composeServiceName.MatchString(name) {
+			errs = append(errs, fmt.Errorf("easypanel: invalid compose service name %q", name))
+		}
+		for _, key := range sortedKeys(svc.Extra) {
+			if hint, ok := unsupportedComposeKeys[key]; ok {
+				errs = append(errs, fmt.Errorf("easypanel: service %q: %q is not supported on swarm: %s", name, key, hint))
+			}
+		}
+		if _, hasBuild := svc.Extra["build"]; svc.Image == "" && !hasBuild {
+			errs = append(errs, fmt.Errorf("easypanel: service %q has no image", name))
+		}
+
+		for _, p := range svc.Ports {
+			if p.Raw != nil {
+				continue
+			}
+			if err := p.validate(); err != nil {
+				errs = append(errs, fmt.Errorf("easypanel: service %q: %w", name, err))
+				continue
+			}
+			if p.Published == 0 {
+				continue
+			}
+			last := max(p.Published, p.PublishedEnd)
+			for port := p.Published; port <= last; port++ {
+				if owner, ok := reservedComposePorts[port]; ok {
+					errs = append(errs, fmt.Errorf("easypanel: service %q: host port %d is used by %s", name, port, owner))
+				}
+				key := fmt.Sprintf("%d/%s", port, p.protocol())
+				if other, ok := published[key]; ok {
+					errs = append(errs, fmt.Errorf("easypanel: service %q: host port %s is already published by service %q", name, key, other))
+					continue
+				}
+				published[key] = name
+			}
+		}
+
+		for _, m := range svc.Volumes {
+			if m.Target == "" || !path.IsAbs(m.Target) && !hasComposeVariable(m.Target) {
+				errs = append(errs, fmt.Errorf("easypanel: service %q: mount target %q must be an absolute path", name, m.Target))
+			}
+			if m.mountType() != "volume" || m.Source == "" || hasComposeVariable(m.Source) {
+				continue
+			}
+			if _, ok := c.Volumes[m.Source]; !ok {
+				errs = append(errs, fmt.Errorf("easypanel: service %q: volume %q is not declared under top-level volumes", name, m.Source))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p ComposePort) protocol() string {
+	if p.Protocol == "" {
+		return "tcp"
+	}
+	return p.Protocol
+}
+
+func (p ComposePort) validate() error {
+	if p.Target < 1 || p.Target > 65535 {
+		return fmt.Errorf("invalid container port %d", p.Target)
+	}
+	if p.Published < 0 || p.Published > 65535 {
+		return fmt.Errorf("invalid host port %d", p.Published)
+	}
+	if p.PublishedEnd != 0 && (p.PublishedEnd < p.Published || p.PublishedEnd > 65535) {
+		return fmt.Errorf("invalid host port range %d-%d", p.Published, p.PublishedEnd)
+	}
+	if p.TargetEnd != 0 {
+		if p.TargetEnd < p.Target || p.TargetEnd > 65535 {
+			return fmt.Errorf("invalid container port range %d-%d", p.Target, p.TargetEnd)
+		}
+		if p.Published != 0 && max(p.Published, p.PublishedEnd)-p.Published != p.TargetEnd-p.Target {
+			return fmt.Errorf("host and container port ranges %s and %s differ in size", p.publishedString(), p.targetString())
+		}
+		if p.Mode != "" || len(p.Extra) > 0 {
+			return fmt.Errorf("container port range %s cannot use the long syntax", p.targetString())
+		}
+	}
+	if proto := p.protocol(); proto != "tcp" && proto != "udp" {
+		return fmt.Errorf("invalid port protocol %q", p.Protocol)
+	}
+	if p.Mode != "" && p.Mode != "ingress" && p.Mode != "host" {
+		return fmt.Errorf("invalid port mode %q", p.Mode)
+	}
+	return nil
+}
+
+func (m ComposeMount) mountType() string {
+	if m.Type != "" {
+		return m.Type
+	}
+	if m.Source == "" || !(strings.HasPrefix(m.Source, "/") || strings.HasPrefix(m.Source, ".") || strings.HasPrefix(m.Source, "~")) {
+		return "volume"
+	}
+	return "bind"
+}
+
+// UnmarshalYAML accepts both the mapping and the list syntax.
+func (e *ComposeEnv) UnmarshalYAML(value *yaml.Node) error {
+	env := make(ComposeEnv)
+	switch value.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			k, v := value.Content[i], value.Content[i+1]
+			if v.Tag == "!!null" {
+				env[k.Value] = nil
+				continue
+			}
+			if v.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: environment variable %q must be a scalar", v.Line, k.Value)
+			}
+			env[k.Value] = &v.Value
+		}
+	case yaml.SequenceNode:
+		for _, item := range value.Content {
+			k, v, ok := strings.Cut(item.Value, "=")
+			if !ok {
+				env[k] = nil
+				continue
+			}
+			env[k] = &v
+		}
+	default:
+		return fmt.Errorf("line %d: environment must be a mapping or a list", value.Line)
+	}
+	*e = env
+	return nil
+}
+
+type composePortLong struct {
+	Target    int            `yaml:"target"`
+	Published string         `yaml:"published,omitempty"`
+	Protocol  string         `yaml:"protocol,omitempty"`
+	HostIP    string         `yaml:"host_ip,omitempty"`
+	Mode      string         `yaml:"mode,omitempty"`
+	Extra     map[string]any `yaml:",inline"`
+}
+
+// UnmarshalYAML accepts the short ("[ip:]published:target[/protocol]") and
+// long port syntax.
+func (ps *ComposePorts) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.SequenceNode {
+		return fmt.Errorf("line %d: ports must be a list", value.Line)
+	}
+	var out ComposePorts
+	for _, item := range value.Content {
+		if nodeHasComposeVariable(item) {
+			var raw any
+			if err := item.Decode(&raw); err != nil {
+				return err
+			}
+			out = append(out, ComposePort{Raw: raw})
+			continue
+		}
+		if item.Kind == yaml.MappingNode {
+			var long composePortLong
+			if err := item.Decode(&long); err != nil {
+				return err
+			}
+			p := ComposePort{Target: long.Target, Protocol: long.Protocol, HostIP: long.HostIP, Mode: long.Mode, Extra: long.Extra}
+			if long.Published != "" {
+				from, to, err := parsePortRange(long.Published)
+				if err != nil {
+					return fmt.Errorf("line %d: invalid published port %q", item.Line, long.Published)
+				}
+				p.Published = from
+				if to != from {
+					p.PublishedEnd = to
+				}
+			}
+			out = append(out, p)
+			continue
+		}
+		p, err := parseComposePort(item.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", item.Line, err)
+		}
+		out = append(out, p)
+	}
+	*ps = out
+	return nil
+}
+
+// parseComposePort parses one short-syntax port entry.
+func parseComposePort(s string) (ComposePort, error) {
+	spec, proto, _ := strings.Cut(s, "/")
+
+	var hostIP string
+	if strings.HasPrefix(spec, "[") {
+		end := strings.Index(spec, "]:")
+		if end < 0 {
+			return ComposePort{}, fmt.Errorf("invalid port %q", s)
+		}
+		hostIP, spec = spec[1:end], spec[end+2:]
+	}
+	parts := strings.Split(spec, ":")
+	var published, target string
+	switch len(parts) {
+	case 1:
+		target = parts[0]
+	case 2:
+		published, target = parts[0], parts[1]
+	case 3:
+		if hostIP != "" {
+			return ComposePort{}, fmt.Errorf("invalid port %q", s)
+		}
+		hostIP, published, target = parts[0], parts[1], parts[2]
+	default:
+		return ComposePort{}, fmt.Errorf("invalid port %q", s)
+	}
+
+	p := ComposePort{Protocol: proto, HostIP: hostIP}
+	var targetTo, pubTo int
+	var err error
+	if p.Target, targetTo, err = parsePortRange(target); err != nil {
+		return ComposePort{}, fmt.Errorf("invalid port %q", s)
+	}
+	if targetTo != p.Target {
+		p.TargetEnd = targetTo
+	}
+	if published != "" {
+		if p.Published, pubTo, err = parsePortRange(published); err != nil {
+			return ComposePort{}, fmt.Errorf("invalid port %q", s)
+		}
+		if pubTo != p.Published {
+			p.PublishedEnd = pubTo
+		}
+		// A host range for one container port lets swarm pick a free host
+		// port; otherwise both ranges map port by port.
+		if p.TargetEnd != 0 && pubTo-p.Published != targetTo-p.Target {
+			return ComposePort{}, fmt.Errorf("port %q: host and container ranges differ in size", s)
+		}
+	}
+	return p, nil
+}
+
+func parsePortRange(s string) (from, to int, err error) {
+	lo, hi, isRange := strings.Cut(s, "-")
+	if from, err = strconv.Atoi(lo); err != nil {
+		return 0, 0, err
+	}
+	to = from
+	if isRange {
+		if to, err = strconv.Atoi(hi); err != nil {
+			return 0, 0, err
+		}
+	}
+	if to < from {
+		return 0, 0, fmt.Errorf("descending port range %q", s)
+	}
+	return from, to, nil
+}
+
+// MarshalYAML writes the short syntax unless the port needs the long one.
+func (p ComposePort) MarshalYAML() (any, error) {
+	if p.Raw != nil {
+		return p.Raw, nil
+	}
+	if p.Mode != "" || len(p.Extra) > 0 {
+		long := composePortLong{Target: p.Target, Protocol: p.Protocol, HostIP: p.HostIP, Mode: p.Mode, Extra: p.Extra}
+		if p.Published != 0 {
+			long.Published = p.publishedString()
+		}
+		return long, nil
+	}
+	s := p.targetString()
+	if p.Published != 0 {
+		s = p.publishedString() + ":" + s
+		if p.HostIP != "" {
+			ip := p.HostIP
+			if net.ParseIP(ip) != nil && strings.Contains(ip, ":") {
+				ip = "[" + ip + "]"
+			}
+			s = ip + ":" + s
+		}
+	}
+	if p.Protocol != "" {
+		s += "/" + p.Protocol
+	}
+	return s, nil
+}
+
+func (p ComposePort) targetString() string {
+	if p.TargetEnd > p.Target {
+		return strconv.Itoa(p.Target) + "-" + strconv.Itoa(p.TargetEnd)
+	}
+	return strconv.Itoa(p.Target)
+}
+
+func (p ComposePort) publishedString() string {
+	if p.PublishedEnd > p.Published {
+		return strconv.Itoa(p.Published) + "-" + strconv.Itoa(p.PublishedEnd)
+	}
+	return strconv.Itoa(p.Published)
+}
+
+type composeMountLong struct {
+	Type     string         `yaml:"type"`
+	Source   string         `yaml:"source,omitempty"`
+	Target   string         `yaml:"target"`
+	ReadOnly bool           `yaml:"read_only,omitempty"`
+	Extra    map[string]any `yaml:",inline"`
+}
+
+// UnmarshalYAML accepts the short ("source:target[:ro]") and long mount
+// syntax.
+func (m *ComposeMount) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode {
+		var long composeMountLong
+		if err := value.Decode(&long); err != nil {
+			return err
+		}
+		*m = ComposeMount{Type: long.Type, Source: long.Source, Target: long.Target, ReadOnly: long.ReadOnly, Extra: long.Extra}
+		return nil
+	}
+	parts := splitComposeColons(value.Value)
+	switch len(parts) {
+	case 1:
+		*m = ComposeMount{Target: parts[0]}
+	case 2, 3:
+		*m = ComposeMount{Source: parts[0], Target: parts[1]}
+		if len(parts) == 3 {
+			for _, opt := range strings.Split(parts[2], ",") {
+				switch opt {
+				case "ro":
+					m.ReadOnly = true
+				case "rw":
+				default:
+					return fmt.Errorf("line %d: unsupported mount option %q", value.Line, opt)
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("line %d: invalid mount %q", value.Line, value.Value)
+	}
+	return nil
+}
+
+// MarshalYAML writes the short syntax unless the mount needs the long one.
+func (m ComposeMount) MarshalYAML() (any, error) {
+	t := m.mountType()
+	inferred := ComposeMount{Source: m.Source}.mountType()
+	if len(m.Extra) > 0 || t != inferred {
+		return composeMountLong{Type: t, Source: m.Source, Target: m.Target, ReadOnly: m.ReadOnly, Extra: m.Extra}, nil
+	}
+	if m.Source == "" {
+		return m.Target, nil
+	}
+	s := m.Source + ":" + m.Target
+	if m.ReadOnly {
+		s += ":ro"
+	}
+	return s, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// hasComposeVariable reports whether s uses variable interpolation.
+func hasComposeVariable(s string) bool {
+	return strings.Contains(s, "$")
+}
+
+// nodeHasComposeVariable reports whether any scalar in node uses variable
+// interpolation.
+func nodeHasComposeVariable(node *yaml.Node) bool {
+	if node.Kind == yaml.ScalarNode {
+		return hasComposeVariable(node.Value)
+	}
+	for _, child := range node.Content {
+		if nodeHasComposeVariable(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitComposeColons splits s on colons outside ${...} expressions, so that
+// "${DATA:-./data}:/data" has two parts.
+func splitComposeColons(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "${"):
+			depth++
+			i++
+		case s[i] == '}' && depth > 0:
+			depth--
+		case s[i] == ':' && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
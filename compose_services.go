@@ -0,0 +1,137 @@
+package easypanel
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// composeReplicaSuffix matches the replica or task suffix Docker appends to a
+// compose service's container name: "-1", "_1" or ".1.<task id>".
+var composeReplicaSuffix = regexp.MustCompile(`[-_.]\d+(\.[a-z0-9]+)?$`)
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// ComposeServices returns the sorted service names declared in the inline
+// compose content of s. Compose services deployed from Git carry no content;
+// use ServicesService.ListComposeServices for those.
+func (s Service) ComposeServices() ([]string, error) {
+	name := s.name()
+	if s.Type != ServiceTypeCompose {
+		return nil, fmt.Errorf("easypanel: service %q has type %q, not compose", name, s.Type)
+	}
+	if s.Source == nil || s.Source.ComposeContent == "" {
+		return nil, fmt.Errorf("easypanel: compose service %q has no inline compose content", name)
+	}
+	spec, err := ParseCompose(s.Source.ComposeContent)
+	if err != nil {
+		return nil, err
+	}
+	return sortedKeys(spec.Services), nil
+}
+
+// ListComposeServices returns the sorted names of the services declared in a
+// compose stack's inline compose content; see Service.ComposeServices. For
+// stacks deployed from Git, parse the compose file of the repository with
+// ParseCompose instead.
+func (s *ServicesService) ListComposeServices(ctx context.Context, params SelectService) ([]string, error) {
+	resp, err := s.Inspect(ctx, ServiceTypeCompose, params)
+	if err != nil {
+		return nil, err
+	}
+	svc := resp.Result.Data.JSON
+	svc.Type = ServiceTypeCompose
+	if svc.name() == "" {
+		svc.ServiceName = params.ServiceName
+	}
+	return svc.ComposeServices()
+}
+
+// ComposeServiceStats is the combined usage of the containers of one service
+// inside a compose stack.
+type ComposeServiceStats struct {
+	Name          string
+	Containers    int
+	CPUPercent    float64 // Sum over containers
+	MemoryUsage   int     // Bytes, sum over containers
+	MemoryPercent float64 // Sum over containers
+	NetworkIn     int
+	NetworkOut    int
+}
+
+// AggregateComposeStats groups container statistics of the compose stack
+// serviceName in projectName by compose service. Statistics are only reported
+// per container, so the compose service is taken from the container name with
+// the replica suffix removed. Containers of other services are ignored. The
+// result is sorted by name.
+func AggregateComposeStats(stats []ContainerStats, projectName, serviceName string) []ComposeServiceStats {
+	stack := projectName + "_" + serviceName
+	byName := make(map[string]*ComposeServiceStats)
+	for _, c := range stats {
+		name, ok := composeServiceOf(c.ContainerName, stack)
+		if !ok {
+			continue
+		}
+		agg, ok := byName[name]
+		if !ok {
+			agg = &ComposeServiceStats{Name: name}
+			byName[name] = agg
+		}
+		agg.Containers++
+		agg.CPUPercent += c.Stats.CPU.Percent
+		agg.MemoryUsage += c.Stats.Memory.Usage
+		agg.MemoryPercent += c.Stats.Memory.Percent
+		agg.NetworkIn += c.Stats.Network.In
+		agg.NetworkOut += c.Stats.Network.Out
+	}
+
+	out := make([]ComposeServiceStats, 0, len(byName))
+	for _, name := range sortedKeys(byName) {
+		out = append(out, *byName[name])
+	}
+	return out
+}
+
+// ComposeStats returns the current usage of each service inside a compose
+// stack; see AggregateComposeStats.
+func (s *MonitorService) ComposeStats(ctx context.Context, params SelectService) ([]ComposeServiceStats, error) {
+	resp, err := s.GetMonitorTableData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return AggregateComposeStats(resp.Result.Data.JSON, params.ProjectName, params.ServiceName), nil
+}
+
+// composeServiceOf extracts the compose service from a container name such as
+// "proj_stack-web-1" or "proj_stack_web.1.abc123" belonging to stack
+// "proj_stack".
+func composeServiceOf(containerName, stack string) (string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimPrefix(containerName, "/"), stack)
+	if !ok || len(rest) < 2 || (rest[0] != '-' && rest[0] != '_') {
+		return "", false
+	}
+	name := composeReplicaSuffix.ReplaceAllString(rest[1:], "")
+	return name, name != ""
+}
+
+// filterComposeLogs keeps the lines of output that "docker compose logs"
+// attributes to composeService, recognised by their "name-1  | " prefix.
+func filterComposeLogs(output, stack, composeService string) string {
+	var kept strings.Builder
+	for _, line := range strings.SplitAfter(output, "\n") {
+		head, _, ok := strings.Cut(ansiEscape.ReplaceAllString(line, ""), "|")
+		if !ok {
+			continue
+		}
+		container := strings.TrimSpace(head)
+		name, ok := composeServiceOf(container, stack)
+		if !ok {
+			name = composeReplicaSuffix.ReplaceAllString(container, "")
+		}
+		if name == composeService {
+			kept.WriteString(line)
+		}
+	}
+	return kept.String()
+}
@@ -0,0 +1,166 @@
+package easypanel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func composeContainer(name string, cpu float64, memBytes int) ContainerStats {
+	var c ContainerStats
+	c.ContainerName = name
+	c.Stats.CPU.Percent = cpu
+	c.Stats.Memory.Usage = memBytes
+	c.Stats.Network.In = 10
+	return c
+}
+
+func TestServiceComposeServices(t *testing.T) {
+	svc := Service{
+		SelectService: SelectService{ProjectName: "proj", ServiceName: "stack"},
+		Type:          ServiceTypeCompose,
+		Source:        &ServiceSource{ComposeContent: "services:\n  worker:\n    image: a\n  api:\n    image: b\n"},
+	}
+	names, err := svc.ComposeServices()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api", "worker"}, names)
+
+	svc.Source = nil
+	_, err = svc.ComposeServices()
+	assert.ErrorContains(t, err, `compose service "stack" has no inline compose content`)
+
+	_, err = Service{Name: "web", Type: ServiceTypeApp}.ComposeServices()
+	assert.ErrorContains(t, err, `service "web" has type "app", not compose`)
+}
+
+func TestComposeServiceOf(t *testing.T) {
+	tests := map[string]string{
+		"proj_stack-web-1":              "web",
+		"/proj_stack-api-server-12":     "api-server",
+		"proj_stack_worker_1":           "worker",
+		"proj_stack_db.1.x7k2p9q0abcde": "db",
+	}
+	for container, want := range tests {
+		got, ok := composeServiceOf(container, "proj_stack")
+		assert.True(t, ok, container)
+		assert.Equal(t, want, got, container)
+	}
+
+	for _, container := range []string{"proj_stacked-web-1", "other_stack-web-1", "proj_stack", "proj_stack-"} {
+		_, ok := composeServiceOf(container, "proj_stack")
+		assert.False(t, ok, container)
+	}
+}
+
+func TestAggregateComposeStats(t *testing.T) {
+	stats := []ContainerStats{
+		composeContainer("proj_stack-web-1", 10, 100),
+		composeContainer("proj_stack-web-2", 15, 200),
+		composeContainer("proj_stack-db-1", 5, 1000),
+		composeContainer("proj_api.1.abc", 90, 5000),
+	}
+
+	assert.Equal(t, []ComposeServiceStats{
+		{Name: "db", Containers: 1, CPUPercent: 5, MemoryUsage: 1000, NetworkIn: 10},
+		{Name: "web", Containers: 2, CPUPercent: 25, MemoryUsage: 300, NetworkIn: 20},
+	}, AggregateComposeStats(stats, "proj", "stack"))
+}
+
+func TestMonitorComposeStats(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/trpc/monitor.getMonitorTableData", r.URL.Path)
+		writeJSON(t, w, newRestResponse([]ContainerStats{composeContainer("proj_stack-web-1", 10, 100)}))
+	})
+
+	stats, err := client.Monitor.ComposeStats(context.Background(), SelectService{ProjectName: "proj", ServiceName: "stack"})
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, "web", stats[0].Name)
+}
+
+func TestServicesListComposeServices(t *testing.T) {
+	t.Run("inline content", func(t *testing.T) {
+		client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/trpc/services.compose.inspectService", r.URL.Path)
+			writeJSON(t, w, newRestResponse(Service{Source: &ServiceSource{ComposeContent: "services:\n  web:\n    image: nginx\n"}}))
+		})
+
+		names, err := client.Services.ListComposeServices(context.Background(), SelectService{ProjectName: "proj", ServiceName: "stack"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"web"}, names)
+	})
+
+	t.Run("git source", func(t *testing.T) {
+		client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/trpc/services.compose.inspectService", r.URL.Path)
+			writeJSON(t, w, newRestResponse(Service{Source: &ServiceSource{ComposeFile: "docker-compose.yml"}}))
+		})
+
+		_, err := client.Services.ListComposeServices(context.Background(), SelectService{ProjectName: "proj", ServiceName: "stack"})
+		assert.ErrorContains(t, err, `compose service "stack" has no inline compose content`)
+	})
+}
+
+func TestServicesStreamLogs_ComposeService(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	var query map[string][]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for _, out := range []string{
+			"web-1  | GET /\r\n",
+			"db-1   | checkpoint\r\nweb-2  | GET /health\r\n",
+			"\x1b[36mweb-1  |\x1b[0m POST /login\r\n",
+			"proj_stack_web_1 | legacy format\r\n",
+			"webhook-1 | not web\r\n",
+		} {
+			if err := conn.WriteJSON(LogMessage{Output: out}); err != nil {
+				return
+			}
+		}
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	}))
+	defer server.Close()
+
+	client := New(Config{Endpoint: server.URL, Token: "test-token"})
+	ch, err := client.Services.StreamLogs(context.Background(), StreamLogsParams{
+		ProjectName:    "proj",
+		ServiceName:    "stack",
+		Token:          "deploy-token",
+		ComposeService: "web",
+	})
+	require.NoError(t, err)
+
+	var received []string
+	for msg := range ch {
+		received = append(received, msg.Output)
+	}
+	assert.Equal(t, []string{
+		"web-1  | GET /\r\n",
+		"web-2  | GET /health\r\n",
+		"\x1b[36mweb-1  |\x1b[0m POST /login\r\n",
+		"proj_stack_web_1 | legacy format\r\n",
+	}, received)
+	assert.Equal(t, []string{"true"}, query["compose"])
+	assert.NotContains(t, query, "composeService", "the panel only knows the compose flag")
+}
+
+func TestNewComposeServiceDomain(t *testing.T) {
+	d := NewComposeServiceDomain("proj", "stack", "web", "app.example.com", 8080)
+	require.NoError(t, d.Validate())
+	assert.Equal(t, "web", d.ServiceDestination.ComposeService)
+	assert.Equal(t, "stack", d.ServiceDestination.ServiceName)
+
+	d.ServiceDestination.ComposeService = "-bad name"
+	assert.ErrorContains(t, d.Validate(), `invalid compose service name "-bad name"`)
+}
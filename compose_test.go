@@ -0,0 +1,252 @@
+package easypanel
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCompose = `version: "3.8"
+services:
+  web:
+    image: nginx:1.27
+    environment:
+      - APP_ENV=production
+      - BLANK=
+      - EMPTY
+    ports:
+      - "8080:80"
+      - "127.0.0.1:9000-9001:9000-9001/udp"
+      - target: 443
+        published: "8443"
+        mode: host
+    volumes:
+      - data:/var/lib/data
+      - ./conf:/etc/nginx/conf.d:ro
+      - /tmp/cache
+    healthcheck:
+      test: ["CMD", "curl", "-f", "http://localhost"]
+  worker:
+    image: busybox
+    command: ["sleep", "infinity"]
+    environment:
+      QUEUE: jobs
+      UNSET:
+volumes:
+  data:
+x-common:
+  restart: always
+`
+
+func TestParseCompose(t *testing.T) {
+	spec, err := ParseCompose(testCompose)
+	require.NoError(t, err)
+	require.NoError(t, spec.Validate())
+
+	production, blank, jobs := "production", "", "jobs"
+	web := spec.Services["web"]
+	assert.Equal(t, "nginx:1.27", web.Image)
+	assert.Equal(t, ComposeEnv{"APP_ENV": &production, "BLANK": &blank, "EMPTY": nil}, web.Environment)
+	assert.Equal(t, ComposePorts{
+		{Target: 80, Published: 8080},
+		{Target: 9000, TargetEnd: 9001, Published: 9000, PublishedEnd: 9001, Protocol: "udp", HostIP: "127.0.0.1"},
+		{Target: 443, Published: 8443, Mode: "host"},
+	}, web.Ports)
+	assert.Equal(t, []ComposeMount{
+		{Source: "data", Target: "/var/lib/data"},
+		{Source: "./conf", Target: "/etc/nginx/conf.d", ReadOnly: true},
+		{Target: "/tmp/cache"},
+	}, web.Volumes)
+	assert.Contains(t, web.Extra, "healthcheck")
+
+	worker := spec.Services["worker"]
+	assert.Equal(t, ComposeEnv{"QUEUE": &jobs, "UNSET": nil}, worker.Environment)
+	assert.Equal(t, []any{"sleep", "infinity"}, worker.Extra["command"])
+	assert.Contains(t, spec.Extra, "x-common")
+}
+
+func TestParseCompose_Invalid(t *testing.T) {
+	for _, content := range []string{
+		"services: [",
+		"services:\n  web:\n    ports:\n      - \"80-81:80-82\"\n",
+		"services:\n  web:\n    ports:\n      - \"80:80-82\"\n",
+		"services:\n  web:\n    ports:\n      - \"a:b\"\n",
+		"services:\n  web:\n    volumes:\n      - a:/b:nocopy\n",
+	} {
+		_, err := ParseCompose(content)
+		assert.Error(t, err, content)
+	}
+}
+
+func TestParseCompose_Variables(t *testing.T) {
+	const content = `services:
+  web:
+    image: ${IMAGE:-nginx}
+    ports:
+      - "${PORT:-8081}:81"
+      - target: 82
+        published: "${ADMIN_PORT}"
+      - "8000-8002:80"
+    volumes:
+      - ${DATA_DIR:-./data}:/data
+`
+	spec, err := ParseCompose(content)
+	require.NoError(t, err)
+	require.NoError(t, spec.Validate())
+
+	web := spec.Services["web"]
+	assert.Equal(t, ComposePorts{
+		{Raw: "${PORT:-8081}:81"},
+		{Raw: map[string]any{"target": 82, "published": "${ADMIN_PORT}"}},
+		{Target: 80, Published: 8000, PublishedEnd: 8002},
+	}, web.Ports)
+	assert.Equal(t, []ComposeMount{{Source: "${DATA_DIR:-./data}", Target: "/data"}}, web.Volumes)
+
+	out, err := spec.Marshal()
+	require.NoError(t, err)
+	assert.Contains(t, out, "- ${PORT:-8081}:81\n")
+	assert.Contains(t, out, "published: ${ADMIN_PORT}\n")
+	assert.Contains(t, out, "- 8000-8002:80\n")
+	assert.Contains(t, out, "- ${DATA_DIR:-./data}:/data\n")
+
+	again, err := ParseCompose(out)
+	require.NoError(t, err)
+	assert.Equal(t, spec, again)
+}
+
+func TestComposeSpecValidate_HostRange(t *testing.T) {
+	spec, err := ParseCompose("services:\n  web:\n    image: nginx\n    ports:\n      - \"2999-3001:80\"\n  api:\n    image: api\n    ports:\n      - \"3001:8080\"\n")
+	require.NoError(t, err)
+	err = spec.Validate()
+	assert.ErrorContains(t, err, "host port 3000 is used by the panel UI")
+	assert.ErrorContains(t, err, `host port 3001/tcp is already published by service "api"`)
+}
+
+func TestComposeSpecMarshal_RoundTrip(t *testing.T) {
+	spec, err := ParseCompose(testCompose)
+	require.NoError(t, err)
+
+	out, err := spec.Marshal()
+	require.NoError(t, err)
+	assert.Contains(t, out, "- 8080:80\n")
+	assert.Contains(t, out, "- 127.0.0.1:9000-9001:9000-9001/udp\n")
+	assert.Contains(t, out, "EMPTY: null\n")
+	assert.Contains(t, out, "BLANK: \"\"\n")
+	assert.Contains(t, out, "- ./conf:/etc/nginx/conf.d:ro\n")
+	assert.Contains(t, out, "x-common:\n")
+
+	again, err := ParseCompose(out)
+	require.NoError(t, err)
+	assert.Equal(t, spec, again)
+}
+
+func TestComposeSpecValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr []string
+	}{
+		{
+			name:    "build is not supported",
+			content: "services:\n  app:\n    build: .\n    container_name: app\n",
+			wantErr: []string{`service "app": "build" is not supported`, `"container_name" is not supported`},
+		},
+		{
+			name:    "missing image",
+			content: "services:\n  app:\n    command: run\n",
+			wantErr: []string{`service "app" has no image`},
+		},
+		{
+			name:    "host port conflict",
+			content: "services:\n  a:\n    image: x\n    ports: [\"8080:80\"]\n  b:\n    image: y\n    ports: [\"8080:8080\", \"8080:8080/udp\"]\n",
+			wantErr: []string{`service "b": host port 8080/tcp is already published by service "a"`},
+		},
+		{
+			name:    "reserved port",
+			content: "services:\n  a:\n    image: x\n    ports: [\"443:8443\"]\n",
+			wantErr: []string{"host port 443 is used by the panel's HTTPS proxy"},
+		},
+		{
+			name:    "undeclared volume",
+			content: "services:\n  a:\n    image: x\n    volumes: [\"data:/data\"]\n",
+			wantErr: []string{`volume "data" is not declared`},
+		},
+		{
+			name:    "unknown top-level key",
+			content: "services:\n  a:\n    image: x\nservice:\n  b: {}\n",
+			wantErr: []string{`unsupported top-level compose key "service"`},
+		},
+		{
+			name:    "no services",
+			content: "version: '3'\n",
+			wantErr: []string{"compose file has no services"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := ParseCompose(tt.content)
+			require.NoError(t, err)
+			err = spec.Validate()
+			require.Error(t, err)
+			for _, want := range tt.wantErr {
+				assert.ErrorContains(t, err, want)
+			}
+		})
+	}
+}
+
+func TestComposeSpecInline(t *testing.T) {
+	dbURL := "postgres://db/app"
+	spec := &ComposeSpec{
+		Services: map[string]ComposeServiceSpec{
+			"api": {
+				Image:       "ghcr.io/acme/api:1.2.0",
+				Environment: ComposeEnv{"DATABASE_URL": &dbURL},
+				Ports:       ComposePorts{{Target: 8080, Published: 8081}},
+				Volumes:     []ComposeMount{{Source: "uploads", Target: "/uploads"}, {Type: "bind", Source: "shared", Target: "/shared"}},
+			},
+		},
+		Volumes: map[string]any{"uploads": nil},
+	}
+
+	params, err := spec.Inline("proj", "stack")
+	require.NoError(t, err)
+	assert.Equal(t, "docker-compose.yml", params.ComposeFile)
+	assert.Contains(t, params.ComposeContent, "- 8081:8080\n")
+	assert.Contains(t, params.ComposeContent, "- uploads:/uploads\n")
+	assert.Contains(t, params.ComposeContent, "type: bind\n")
+
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/trpc/services.compose.updateSourceInline", r.URL.Path)
+		var body UpdateSourceInline
+		decodeTRPCBody(t, r, &body)
+		assert.Equal(t, params, body)
+		w.WriteHeader(http.StatusOK)
+	})
+	require.NoError(t, client.Services.UpdateSourceInline(context.Background(), ServiceTypeCompose, params))
+
+	delete(spec.Volumes, "uploads")
+	_, err = spec.Inline("proj", "stack")
+	assert.ErrorContains(t, err, `volume "uploads" is not declared`)
+}
+
+func TestServicesUpdateSourceInline_PassThrough(t *testing.T) {
+	params := UpdateSourceInline{
+		ProjectName:    "proj",
+		ServiceName:    "stack",
+		ComposeFile:    "docker-compose.yml",
+		ComposeContent: "services:\n  web:\n    build: .\n    ports:\n      - \"${PORT:-8081}:81\"\n",
+	}
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body UpdateSourceInline
+		decodeTRPCBody(t, r, &body)
+		assert.Equal(t, params, body)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	require.NoError(t, client.Services.UpdateSourceInline(context.Background(), ServiceTypeCompose, params))
+}
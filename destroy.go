@@ -0,0 +1,180 @@
+package easypanel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// DestroyOptions controls ProjectsService.SafeDestroy.
+type DestroyOptions struct {
+	// Confirm must equal the project name unless DryRun is set.
+	Confirm string
+	// DryRun reports what would be deleted, and writes the export if Export
+	// is set, without backing up or deleting anything.
+	DryRun bool
+	// Export, when set, receives the project and the full configuration of
+	// every service as indented JSON before anything is deleted.
+	Export io.Writer
+	// BackupDatabases runs an on-demand backup of every database service and
+	// waits for it to finish before deleting anything. Databases without a
+	// backup destination make the destroy fail.
+	BackupDatabases bool
+	// BackupPollInterval is how often backups are checked for completion.
+	// Defaults to 5s.
+	BackupPollInterval time.Duration
+	// BackupTimeout bounds the wait for each database backup. Defaults to 30m.
+	BackupTimeout time.Duration
+	// OnProgress, when set, is called before and after each service is
+	// destroyed.
+	OnProgress func(ServiceProgress)
+}
+
+// DestroyReport describes what ProjectsService.SafeDestroy found and did.
+type DestroyReport struct {
+	ProjectName string
+	Services    []Service // Services as listed by Projects.Inspect
+	Backups     []Backup  // Backups taken before deleting
+	DryRun      bool
+	Destroyed   bool
+}
+
+// SafeDestroy destroys project name and all its services after a series of
+// safety steps: the services are enumerated with Inspect, the configuration
+// is optionally exported, database backups are optionally taken, and the
+// deletion only proceeds if opts.Confirm equals the project name. The project
+// is then removed as by Destroy.
+func (s *ProjectsService) SafeDestroy(ctx context.Context, name string, opts DestroyOptions) (DestroyReport, error) {
+	report := DestroyReport{ProjectName: name, DryRun: opts.DryRun}
+	if !opts.DryRun && opts.Confirm != name {
+		return report, fmt.Errorf("easypanel: destroy %q: confirmation %q does not match the project name", name, opts.Confirm)
+	}
+	if opts.BackupPollInterval <= 0 {
+		opts.BackupPollInterval = 5 * time.Second
+	}
+	if opts.BackupTimeout <= 0 {
+		opts.BackupTimeout = 30 * time.Minute
+	}
+
+	inspected, err := s.Inspect(ctx, ProjectQuery{ProjectName: name})
+	if err != nil {
+		return report, fmt.Errorf("easypanel: destroy %q: %w", name, err)
+	}
+	project := inspected.Result.Data.JSON
+	report.Services = project.Services
+
+	svcs := s.services()
+	if opts.Export != nil {
+		if err := exportProject(ctx, svcs, project, opts.Export); err != nil {
+			return report, fmt.Errorf("easypanel: destroy %q: export: %w", name, err)
+		}
+	}
+	if opts.DryRun {
+		return report, nil
+	}
+
+	if opts.BackupDatabases {
+		for _, svc := range project.Services {
+			if !svc.Type.IsDatabase() {
+				continue
+			}
+			svcName := svc.name()
+			backup, err := runBackupAndWait(ctx, svcs, svc.Type, SelectService{ProjectName: name, ServiceName: svcName}, opts.BackupPollInterval, opts.BackupTimeout)
+			if err != nil {
+				return report, fmt.Errorf("easypanel: destroy %q: backup %s: %w", name, svcName, err)
+			}
+			report.Backups = append(report.Backups, backup)
+		}
+	}
+
+	if err := s.Teardown(ctx, name, opts.OnProgress); err != nil {
+		return report, err
+	}
+	report.Destroyed = true
+	return report, nil
+}
+
+// exportProject writes project with the full configuration of each service.
+func exportProject(ctx context.Context, svcs *ServicesService, project ProjectInspect, w io.Writer) error {
+	full := ProjectInspect{Project: project.Project, Services: make([]Service, 0, len(project.Services))}
+	for _, svc := range project.Services {
+		name := svc.name()
+		resp, err := svcs.Inspect(ctx, svc.Type, SelectService{ProjectName: project.Project.Name, ServiceName: name})
+		if err != nil {
+			return fmt.Errorf("inspect %s: %w", name, err)
+		}
+		detail := resp.Result.Data.JSON
+		if detail.Type == "" {
+			detail.Type = svc.Type
+		}
+		full.Services = append(full.Services, detail)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(full)
+}
+
+// runBackupAndWait triggers a backup of a database service and polls its
+// backups every interval until a backup that did not exist before is done.
+// The service must have a backup destination, and the wait is bounded by
+// timeout.
+func runBackupAndWait(ctx context.Context, svcs *ServicesService, st ServiceType, sel SelectService, interval, timeout time.Duration) (Backup, error) {
+	inspected, err := svcs.Inspect(ctx, st, sel)
+	if err != nil {
+		return Backup{}, err
+	}
+	if cfg := inspected.Result.Data.JSON.Backup; cfg == nil || cfg.DestinationID == "" {
+		return Backup{}, fmt.Errorf("no backup destination configured")
+	}
+	before, err := svcs.ListBackups(ctx, st, sel)
+	if err != nil {
+		return Backup{}, err
+	}
+	seen := make(map[string]bool, len(before.Result.Data.JSON))
+	for _, b := range before.Result.Data.JSON {
+		seen[b.ID] = true
+	}
+	if err := svcs.RunBackup(ctx, st, sel); err != nil {
+		return Backup{}, err
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	// stopped reports why waiting ended: the backup timeout, or whatever
+	// ended the caller's ctx, including an earlier deadline of its own.
+	stopped := func() error {
+		if ctx.Err() == nil && waitCtx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("backup did not finish within %s: %w", timeout, waitCtx.Err())
+		}
+		return ctx.Err()
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-waitCtx.Done():
+			return Backup{}, stopped()
+		case <-ticker.C:
+		}
+		resp, err := svcs.ListBackups(waitCtx, st, sel)
+		if waitCtx.Err() != nil {
+			return Backup{}, stopped()
+		}
+		if err != nil {
+			return Backup{}, err
+		}
+		for _, b := range resp.Result.Data.JSON {
+			if seen[b.ID] {
+				continue
+			}
+			switch b.Status {
+			case "done":
+				return b, nil
+			case "error", "failed":
+				return b, fmt.Errorf("backup %s failed", b.ID)
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package easypanel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func destroyPanel() *recordingPanel {
+	return newRecordingPanel(map[string]any{
+		"projects.inspectProject": ProjectInspect{
+			Project: ProjectInfo{Name: "prod"},
+			Services: []Service{
+				{SelectService: SelectService{ServiceName: "web"}, Type: ServiceTypeApp},
+				{SelectService: SelectService{ServiceName: "db"}, Type: ServiceTypePostgres},
+			},
+		},
+		"services.app.inspectService": Service{SelectService: SelectService{ProjectName: "prod", ServiceName: "web"}, Env: "A=1"},
+		"services.postgres.inspectService": Service{
+			SelectService: SelectService{ProjectName: "prod", ServiceName: "db"},
+			Backup:        &BackupConfig{Enabled: true, DestinationID: "s3"},
+		},
+	})
+}
+
+func TestProjectsSafeDestroy_RequiresConfirmation(t *testing.T) {
+	panel := destroyPanel()
+	client := setupTestClient(t, panel.handler(t))
+
+	_, err := client.Projects.SafeDestroy(context.Background(), "prod", DestroyOptions{Confirm: "production"})
+	assert.ErrorContains(t, err, `confirmation "production" does not match the project name`)
+	assert.Empty(t, panel.order)
+}
+
+func TestProjectsSafeDestroy_DryRunExport(t *testing.T) {
+	panel := destroyPanel()
+	client := setupTestClient(t, panel.handler(t))
+
+	var export bytes.Buffer
+	report, err := client.Projects.SafeDestroy(context.Background(), "prod", DestroyOptions{DryRun: true, Export: &export, BackupDatabases: true})
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.False(t, report.Destroyed)
+	assert.Len(t, report.Services, 2)
+	assert.Empty(t, panel.order, "a dry run changes nothing")
+
+	var spec ProjectInspect
+	require.NoError(t, json.Unmarshal(export.Bytes(), &spec))
+	require.Len(t, spec.Services, 2)
+	assert.Equal(t, "A=1", spec.Services[0].Env)
+	assert.Equal(t, ServiceTypePostgres, spec.Services[1].Type)
+}
+
+func TestProjectsSafeDestroy_BackupThenDestroy(t *testing.T) {
+	panel := destroyPanel()
+	var lists atomic.Int32
+	handler := panel.handler(t)
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/trpc/services.postgres.listBackups" {
+			handler(w, r)
+			return
+		}
+		backups := []Backup{{ID: "old", Status: "done"}}
+		switch n := lists.Add(1); {
+		case n == 2:
+			backups = append(backups, Backup{ID: "new", Status: "running"})
+		case n > 2:
+			backups = append(backups, Backup{ID: "new", Status: "done"})
+		}
+		writeJSON(t, w, newRestResponse(backups))
+	})
+
+	report, err := client.Projects.SafeDestroy(context.Background(), "prod", DestroyOptions{
+		Confirm:            "prod",
+		BackupDatabases:    true,
+		BackupPollInterval: time.Millisecond,
+	})
+	require.NoError(t, err)
+	assert.True(t, report.Destroyed)
+	assert.Equal(t, []Backup{{ID: "new", Status: "done"}}, report.Backups)
+	assert.Equal(t, []string{
+		"services.postgres.runBackup",
+		"services.app.destroyService",
+		"services.postgres.destroyService",
+		"projects.destroyProject",
+	}, panel.order)
+}
+
+func TestProjectsSafeDestroy_BackupFailureKeepsProject(t *testing.T) {
+	panel := destroyPanel()
+	panel.failPost = "services.postgres.runBackup"
+	panel.gets["services.postgres.listBackups"] = []Backup{}
+	client := setupTestClient(t, panel.handler(t))
+
+	report, err := client.Projects.SafeDestroy(context.Background(), "prod", DestroyOptions{Confirm: "prod", BackupDatabases: true})
+	assert.ErrorContains(t, err, `destroy "prod": backup db: rejected`)
+	assert.False(t, report.Destroyed)
+	assert.NotContains(t, panel.order, "projects.destroyProject")
+}
+
+func TestProjectsSafeDestroy_BackupWithoutDestination(t *testing.T) {
+	panel := destroyPanel()
+	panel.gets["services.postgres.inspectService"] = Service{SelectService: SelectService{ProjectName: "prod", ServiceName: "db"}}
+	client := setupTestClient(t, panel.handler(t))
+
+	_, err := client.Projects.SafeDestroy(context.Background(), "prod", DestroyOptions{Confirm: "prod", BackupDatabases: true})
+	assert.ErrorContains(t, err, "backup db: no backup destination configured")
+	assert.Empty(t, panel.order)
+}
+
+func TestProjectsSafeDestroy_BackupFailedStatus(t *testing.T) {
+	panel := destroyPanel()
+	var lists atomic.Int32
+	handler := panel.handler(t)
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/trpc/services.postgres.listBackups" {
+			handler(w, r)
+			return
+		}
+		backups := []Backup{}
+		if lists.Add(1) > 1 {
+			backups = append(backups, Backup{ID: "new", Status: "failed"})
+		}
+		writeJSON(t, w, newRestResponse(backups))
+	})
+
+	_, err := client.Projects.SafeDestroy(context.Background(), "prod", DestroyOptions{
+		Confirm:            "prod",
+		BackupDatabases:    true,
+		BackupPollInterval: time.Millisecond,
+	})
+	assert.ErrorContains(t, err, "backup new failed")
+	assert.NotContains(t, panel.order, "projects.destroyProject")
+}
+
+func TestProjectsSafeDestroy_BackupTimeout(t *testing.T) {
+	panel := destroyPanel()
+	panel.gets["services.postgres.listBackups"] = []Backup{}
+	client := setupTestClient(t, panel.handler(t))
+
+	_, err := client.Projects.SafeDestroy(context.Background(), "prod", DestroyOptions{
+		Confirm:            "prod",
+		BackupDatabases:    true,
+		BackupPollInterval: time.Millisecond,
+		BackupTimeout:      20 * time.Millisecond,
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "backup did not finish within 20ms")
+	assert.NotContains(t, panel.order, "projects.destroyProject")
+}
+
+func TestProjectsSafeDestroy_CallerDeadlineBeforeBackupTimeout(t *testing.T) {
+	panel := destroyPanel()
+	panel.gets["services.postgres.listBackups"] = []Backup{}
+	client := setupTestClient(t, panel.handler(t))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.Projects.SafeDestroy(ctx, "prod", DestroyOptions{
+		Confirm:            "prod",
+		BackupDatabases:    true,
+		BackupPollInterval: time.Millisecond,
+		BackupTimeout:      time.Hour,
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotContains(t, err.Error(), "backup did not finish", "the caller's deadline is not reported as the backup timeout")
+	assert.NotContains(t, panel.order, "projects.destroyProject")
+}
@@ -67,25 +67,18 @@
 //	    ComposeContent: composeYAML,
 //	})
 //
+// Inline content is sent as is. Check it with [ParseCompose] and
+// [ComposeSpec.Validate] to report keys Docker Swarm ignores, such as build, and
+// host port conflicts before deploying rather than at deploy time. A
+// [ComposeSpec] can also be built in code and turned into validated
+// parameters with [ComposeSpec.Inline].
+//
 // # Domains
 //
-// Domains are managed separately from services:
-//
-//	_, err := client.Domains.Create(ctx, easypanel.Domain{
-//	    ID:                  "unique-id",
-//	    HTTPS:               true,
-//	    Host:                "api.example.com",
-//	    Path:                "/",
-//	    CertificateResolver: "letsencrypt",
-//	    DestinationType:     "service",
-//	    ServiceDestination: &easypanel.ServiceDestination{
-//	        Protocol:    "http",
-//	        Port:        80,
-//	        Path:        "/",
-//	        ProjectName: "my-app",
-//	        ServiceName: "api",
-//	    },
-//	})
+// Domains are managed separately from services. [NewServiceDomain] builds a
+// typical HTTPS domain, and [Domain.Validate] runs automatically on create/update:
+//
+//	_, err := client.Domains.Create(ctx, easypanel.NewServiceDomain("my-app", "api", "api.example.com", 80))
 //
 // # Actions
 //
@@ -103,6 +96,16 @@
 //	stats, err := client.Monitor.GetSystemStats(ctx)
 //	containers, err := client.Monitor.GetMonitorTableData(ctx)
 //
+// # Panel Versions
+//
+// Use [Client.ServerInfo] to detect which procedures the panel provides. Operations
+// the panel does not support return an error matching [ErrUnsupported]:
+//
+//	info, err := client.ServerInfo(ctx)
+//	if !info.Capabilities.Domains {
+//	    // use Services.UpdateDomains instead
+//	}
+//
 // # Response Format
 //
 // All responses are wrapped in [RestResponse] which follows the tRPC envelope format.
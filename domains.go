@@ -1,32 +1,271 @@
 package easypanel
 
-import "context"
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
 
 // DomainsService handles domain-related API operations (newer Easypanel API).
 type DomainsService struct {
-	client *httpClient
+	client   *httpClient
+	resolver Resolver
+	dialer   Dialer
 }
 
-// Create creates a new domain.
+// Create creates a new domain. The domain is validated locally before it is sent.
 func (s *DomainsService) Create(ctx context.Context, params CreateDomainParams) (RestResponse[Domain], error) {
 	var resp RestResponse[Domain]
+	if err := params.Validate(); err != nil {
+		return resp, err
+	}
+	if err := s.client.require(routeCreateDomain, hasDomains); err != nil {
+		return resp, err
+	}
 	err := s.client.post(ctx, routeCreateDomain, params, &resp)
 	return resp, err
 }
 
-// Update updates an existing domain.
+// Update updates an existing domain. The domain is validated locally before it is sent.
 func (s *DomainsService) Update(ctx context.Context, params UpdateDomainParams) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	if err := s.client.require(routeUpdateDomain, hasDomains); err != nil {
+		return err
+	}
 	return s.client.post(ctx, routeUpdateDomain, params, nil)
 }
 
 // Delete deletes a domain by ID.
 func (s *DomainsService) Delete(ctx context.Context, params DeleteDomainParams) error {
+	if err := s.client.require(routeDeleteDomain, hasDomains); err != nil {
+		return err
+	}
 	return s.client.post(ctx, routeDeleteDomain, params, nil)
 }
 
 // List returns all domains for a project/service.
 func (s *DomainsService) List(ctx context.Context, params ListDomainsParams) (RestResponse[[]Domain], error) {
 	var resp RestResponse[[]Domain]
+	if err := s.client.require(routeListDomains, hasDomains); err != nil {
+		return resp, err
+	}
 	err := s.client.get(ctx, routeListDomains, params, &resp)
 	return resp, err
 }
+
+// NewServiceDomain returns an HTTPS domain with a Let's Encrypt certificate that
+// routes host to port of the given service over plain HTTP.
+func NewServiceDomain(projectName, serviceName, host string, port int) Domain {
+	return Domain{
+		ID:                  newDomainID(),
+		HTTPS:               true,
+		Host:                host,
+		Path:                "/",
+		Middlewares:         []string{},
+		CertificateResolver: CertificateResolverLetsEncrypt,
+		DestinationType:     DomainDestinationService,
+		ServiceDestination: &ServiceDestination{
+			Protocol:    DomainProtocolHTTP,
+			Port:        port,
+			Path:        "/",
+			ProjectName: projectName,
+			ServiceName: serviceName,
+		},
+	}
+}
+
+// NewComposeServiceDomain is like NewServiceDomain but routes host to port of
+// composeService, one service inside the compose stack serviceName.
+func NewComposeServiceDomain(projectName, serviceName, composeService, host string, port int) Domain {
+	d := NewServiceDomain(projectName, serviceName, host, port)
+	d.ServiceDestination.ComposeService = composeService
+	return d
+}
+
+// Validate checks the domain for mistakes that would otherwise only surface
+// when the panel or Traefik processes it. All problems found are reported.
+// The destination is only checked for DomainDestinationService.
+func (d Domain) Validate() error {
+	var errs []error
+	if err := validateHost(d.Host); err != nil {
+		errs = append(errs, err)
+	}
+	wildcardHost := strings.HasPrefix(d.Host, "*.")
+	if wildcardHost && !d.Wildcard {
+		errs = append(errs, fmt.Errorf("easypanel: host %q is a wildcard but Wildcard is not set", d.Host))
+	}
+	if (d.Wildcard || wildcardHost) && d.HTTPS && d.CertificateResolver == CertificateResolverLetsEncrypt {
+		errs = append(errs, fmt.Errorf("easypanel: wildcard domain %q needs a DNS challenge certificate resolver, not %q", d.Host, d.CertificateResolver))
+	}
+	if !d.HTTPS && d.CertificateResolver != CertificateResolverNone {
+		errs = append(errs, fmt.Errorf("easypanel: certificate resolver %q set on non-HTTPS domain %q", d.CertificateResolver, d.Host))
+	}
+	if err := validatePathPrefix("domain path", d.Path); err != nil {
+		errs = append(errs, err)
+	}
+	for _, m := range d.Middlewares {
+		if m == "" || strings.ContainsAny(m, " \t\n") {
+			errs = append(errs, fmt.Errorf("easypanel: invalid middleware name %q", m))
+		}
+	}
+	// Other destination types, and an empty one, are left to the panel.
+	if d.DestinationType == DomainDestinationService {
+		if d.ServiceDestination == nil {
+			errs = append(errs, fmt.Errorf("easypanel: service destination is required for destination type %q", d.DestinationType))
+		} else if err := d.ServiceDestination.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks that the destination names a service, protocol, port and path.
+func (sd ServiceDestination) Validate() error {
+	var errs []error
+	if sd.ProjectName == "" || sd.ServiceName == "" {
+		errs = append(errs, fmt.Errorf("easypanel: service destination needs a project and service name"))
+	}
+	switch sd.Protocol {
+	case DomainProtocolHTTP, DomainProtocolHTTPS:
+	default:
+		errs = append(errs, fmt.Errorf("easypanel: unknown destination protocol %q", sd.Protocol))
+	}
+	if sd.Port < 1 || sd.Port > 65535 {
+		errs = append(errs, fmt.Errorf("easypanel: destination port %d out of range 1-65535", sd.Port))
+	}
+	if err := validatePathPrefix("destination path", sd.Path); err != nil {
+		errs = append(errs, err)
+	}
+	if sd.ComposeService != "" && !composeServiceName.MatchString(sd.ComposeService) {
+		errs = append(errs, fmt.Errorf("easypanel: invalid compose service name %q", sd.ComposeService))
+	}
+	return errors.Join(errs...)
+}
+
+// validateHost checks that host is an RFC 1123 hostname, optionally with a
+// leading "*." wildcard label.
+func validateHost(host string) error {
+	if host == "" {
+		return fmt.Errorf("easypanel: domain host is required")
+	}
+	if len(host) > 253 {
+		return fmt.Errorf("easypanel: host %q exceeds 253 characters", host)
+	}
+	labels := strings.Split(host, ".")
+	if labels[0] == "*" {
+		labels = labels[1:]
+		if len(labels) < 2 {
+			return fmt.Errorf("easypanel: wildcard host %q must cover a subdomain, e.g. *.example.com", host)
+		}
+	}
+	for _, label := range labels {
+		if err := validateHostLabel(label); err != nil {
+			return fmt.Errorf("easypanel: invalid host %q: %w", host, err)
+		}
+	}
+	return nil
+}
+
+func validateHostLabel(label string) error {
+	if label == "" {
+		return errors.New("empty label")
+	}
+	if len(label) > 63 {
+		return fmt.Errorf("label %q exceeds 63 characters", label)
+	}
+	if label[0] == '-' || label[len(label)-1] == '-' {
+		return fmt.Errorf("label %q must not start or end with a hyphen", label)
+	}
+	for _, r := range label {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+			return fmt.Errorf("label %q contains invalid character %q", label, r)
+		}
+	}
+	return nil
+}
+
+func validatePathPrefix(what, path string) error {
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("easypanel: %s %q must start with \"/\"", what, path)
+	}
+	if strings.ContainsAny(path, " ?#") {
+		return fmt.Errorf("easypanel: %s %q must not contain spaces, query or fragment", what, path)
+	}
+	return nil
+}
+
+// MigrateLegacy creates a Domain record for every entry in svc.Domains that does
+// not already exist in the newer domain API, matching on host and path. With
+// dryRun set, nothing is created and the report lists what would be. Every
+// entry is validated in both modes, so a dry run reports the entries the
+// panel would reject. Failures on individual entries are recorded in the report rather than
+// aborting the migration; the returned error is only set when the existing
+// domains cannot be listed.
+func (s *DomainsService) MigrateLegacy(ctx context.Context, svc Service, dryRun bool) (DomainMigrationReport, error) {
+	serviceName := svc.name()
+	report := DomainMigrationReport{
+		ProjectName: svc.ProjectName,
+		ServiceName: serviceName,
+		DryRun:      dryRun,
+	}
+
+	existing, err := s.List(ctx, ListDomainsParams{ProjectName: svc.ProjectName, ServiceName: serviceName})
+	if err != nil {
+		return report, err
+	}
+	seen := make(map[string]bool)
+	for _, d := range existing.Result.Data.JSON {
+		seen[d.Host+d.Path] = true
+	}
+
+	for _, legacy := range svc.Domains {
+		m := DomainMigration{
+			Legacy: legacy,
+			Domain: domainFromLegacy(svc.ProjectName, serviceName, legacy),
+		}
+		switch {
+		case seen[m.Domain.Host+m.Domain.Path]:
+			m.Skipped = true
+			m.Reason = "domain already exists"
+		default:
+			if m.Err = m.Domain.Validate(); m.Err == nil && !dryRun {
+				_, m.Err = s.Create(ctx, m.Domain)
+			}
+		}
+		seen[m.Domain.Host+m.Domain.Path] = true
+		report.Domains = append(report.Domains, m)
+	}
+	return report, nil
+}
+
+// domainFromLegacy converts a legacy per-service domain entry into the
+// equivalent Domain record pointing at the same service.
+func domainFromLegacy(projectName, serviceName string, p DomainParams) Domain {
+	path := p.Path
+	if path == "" {
+		path = "/"
+	}
+	port := p.Port
+	if port == 0 {
+		port = 80
+	}
+	d := NewServiceDomain(projectName, serviceName, p.Host, port)
+	d.Path = path
+	d.HTTPS = p.HTTPS
+	if !p.HTTPS {
+		d.CertificateResolver = CertificateResolverNone
+	}
+	return d
+}
+
+// newDomainID returns a random identifier for a new Domain record.
+func newDomainID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
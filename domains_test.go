@@ -0,0 +1,195 @@
+package easypanel
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func legacyService() Service {
+	return Service{
+		SelectService: SelectService{ProjectName: "proj", ServiceName: "web"},
+		Type:          ServiceTypeApp,
+		Domains: []DomainParams{
+			{Host: "example.com", HTTPS: true, Port: 3000},
+			{Host: "api.example.com", Path: "/v1"},
+			{Host: "old.example.com"},
+		},
+	}
+}
+
+func TestDomainsMigrateLegacy(t *testing.T) {
+	var created []Domain
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/trpc/domains.listDomains":
+			var input ListDomainsParams
+			decodeTRPCQuery(t, r, &input)
+			assert.Equal(t, ListDomainsParams{ProjectName: "proj", ServiceName: "web"}, input)
+			writeJSON(t, w, newRestResponse([]Domain{{ID: "d0", Host: "old.example.com", Path: "/"}}))
+		case "/api/trpc/domains.createDomain":
+			var body Domain
+			decodeTRPCBody(t, r, &body)
+			created = append(created, body)
+			writeJSON(t, w, newRestResponse(body))
+		default:
+			t.Fatalf("unexpected request %s", r.URL.Path)
+		}
+	})
+
+	report, err := client.Domains.MigrateLegacy(context.Background(), legacyService(), false)
+	require.NoError(t, err)
+	require.Len(t, report.Domains, 3)
+	require.Len(t, created, 2)
+
+	first := created[0]
+	assert.NotEmpty(t, first.ID)
+	assert.Equal(t, "example.com", first.Host)
+	assert.Equal(t, "/", first.Path)
+	assert.True(t, first.HTTPS)
+	assert.Equal(t, CertificateResolverLetsEncrypt, first.CertificateResolver)
+	assert.Equal(t, DomainDestinationService, first.DestinationType)
+	require.NotNil(t, first.ServiceDestination)
+	assert.Equal(t, 3000, first.ServiceDestination.Port)
+	assert.Equal(t, "web", first.ServiceDestination.ServiceName)
+
+	assert.Equal(t, "/v1", created[1].Path)
+	assert.Equal(t, 80, created[1].ServiceDestination.Port)
+	assert.Empty(t, created[1].CertificateResolver)
+
+	assert.True(t, report.Domains[2].Skipped)
+	assert.Equal(t, "domain already exists", report.Domains[2].Reason)
+}
+
+func TestDomainsMigrateLegacy_DryRun(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/trpc/domains.listDomains", r.URL.Path)
+		writeJSON(t, w, newRestResponse([]Domain{}))
+	})
+
+	report, err := client.Domains.MigrateLegacy(context.Background(), legacyService(), true)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	require.Len(t, report.Domains, 3)
+	for _, m := range report.Domains {
+		assert.False(t, m.Skipped)
+		assert.NoError(t, m.Err)
+		assert.Equal(t, m.Legacy.Host, m.Domain.Host)
+	}
+}
+
+func TestDomainsMigrateLegacy_InvalidEntry(t *testing.T) {
+	svc := legacyService()
+	svc.Domains = append(svc.Domains, DomainParams{Host: "bad host", Path: "v2"})
+	for _, dryRun := range []bool{true, false} {
+		var creates int
+		client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/trpc/domains.createDomain" {
+				creates++
+			}
+			writeJSON(t, w, newRestResponse([]Domain{}))
+		})
+
+		report, err := client.Domains.MigrateLegacy(context.Background(), svc, dryRun)
+		require.NoError(t, err)
+		require.Len(t, report.Domains, 4)
+		invalid := report.Domains[3]
+		assert.ErrorContains(t, invalid.Err, `"bad host"`, "dryRun=%v", dryRun)
+		assert.ErrorContains(t, invalid.Err, `"v2" must start with "/"`, "dryRun=%v", dryRun)
+		if dryRun {
+			assert.Zero(t, creates)
+		} else {
+			assert.Equal(t, 3, creates, "the invalid entry is not sent")
+		}
+	}
+}
+
+func TestNewServiceDomain(t *testing.T) {
+	d := NewServiceDomain("proj", "web", "app.example.com", 3000)
+	require.NoError(t, d.Validate())
+	assert.NotEmpty(t, d.ID)
+	assert.True(t, d.HTTPS)
+	assert.Equal(t, CertificateResolverLetsEncrypt, d.CertificateResolver)
+	assert.Equal(t, DomainDestinationService, d.DestinationType)
+	assert.Equal(t, DomainProtocolHTTP, d.ServiceDestination.Protocol)
+	assert.Equal(t, 3000, d.ServiceDestination.Port)
+}
+
+func TestDomainValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(d *Domain)
+		wantErr []string
+	}{
+		{name: "valid", modify: func(d *Domain) {}},
+		{name: "uppercase host", modify: func(d *Domain) { d.Host = "App.Example.com" }},
+		{name: "empty host", modify: func(d *Domain) { d.Host = "" }, wantErr: []string{"host is required"}},
+		{name: "hyphen label", modify: func(d *Domain) { d.Host = "-app.example.com" }, wantErr: []string{`label "-app" must not start or end with a hyphen`}},
+		{name: "underscore", modify: func(d *Domain) { d.Host = "my_app.example.com" }, wantErr: []string{"invalid character '_'"}},
+		{name: "empty label", modify: func(d *Domain) { d.Host = "app..example.com" }, wantErr: []string{"empty label"}},
+		{name: "long label", modify: func(d *Domain) { d.Host = strings.Repeat("a", 64) + ".com" }, wantErr: []string{"exceeds 63 characters"}},
+		{name: "inner wildcard", modify: func(d *Domain) { d.Host = "app.*.example.com" }, wantErr: []string{"invalid character '*'"}},
+		{name: "wildcard without flag", modify: func(d *Domain) {
+			d.Host = "*.example.com"
+			d.CertificateResolver = "cloudflare"
+		}, wantErr: []string{"Wildcard is not set"}},
+		{name: "wildcard with dns resolver", modify: func(d *Domain) {
+			d.Host = "*.example.com"
+			d.Wildcard = true
+			d.CertificateResolver = "cloudflare"
+		}},
+		{name: "wildcard with http challenge", modify: func(d *Domain) { d.Wildcard = true }, wantErr: []string{"needs a DNS challenge"}},
+		{name: "bare wildcard tld", modify: func(d *Domain) {
+			d.Host = "*.com"
+			d.Wildcard = true
+			d.HTTPS = false
+			d.CertificateResolver = ""
+		}, wantErr: []string{"must cover a subdomain"}},
+		{name: "resolver without https", modify: func(d *Domain) { d.HTTPS = false }, wantErr: []string{"non-HTTPS domain"}},
+		{name: "relative path", modify: func(d *Domain) { d.Path = "api" }, wantErr: []string{`domain path "api" must start with "/"`}},
+		{name: "query in path", modify: func(d *Domain) { d.Path = "/api?x=1" }, wantErr: []string{"must not contain"}},
+		{name: "bad middleware", modify: func(d *Domain) { d.Middlewares = []string{"auth file"} }, wantErr: []string{"invalid middleware"}},
+		{name: "other destination type", modify: func(d *Domain) {
+			d.DestinationType = "url"
+			d.ServiceDestination = nil
+		}},
+		{name: "empty destination type", modify: func(d *Domain) {
+			d.DestinationType = ""
+			d.ServiceDestination = nil
+		}},
+		{name: "missing destination", modify: func(d *Domain) { d.ServiceDestination = nil }, wantErr: []string{"service destination is required"}},
+		{name: "destination problems", modify: func(d *Domain) {
+			d.ServiceDestination.Port = 70000
+			d.ServiceDestination.Protocol = "tcp"
+			d.ServiceDestination.ServiceName = ""
+		}, wantErr: []string{"out of range 1-65535", `unknown destination protocol "tcp"`, "needs a project and service name"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewServiceDomain("proj", "web", "app.example.com", 80)
+			tt.modify(&d)
+			err := d.Validate()
+			if len(tt.wantErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, want := range tt.wantErr {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestDomainsCreate_ValidatesLocally(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("no request expected")
+	})
+
+	_, err := client.Domains.Create(context.Background(), NewServiceDomain("proj", "web", "bad host", 80))
+	assert.ErrorContains(t, err, "invalid character ' '")
+}
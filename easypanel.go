@@ -4,8 +4,16 @@ import "context"
 
 // Config holds the configuration for an Easypanel client.
 type Config struct {
-	Endpoint string // Base URL, e.g. "https://panel.example.com"
-	Token    string // Authorization token
+	Endpoint string   // Base URL, e.g. "https://panel.example.com"
+	Token    string   // Authorization token
+	Resolver Resolver // DNS resolver for Domains.Preflight; defaults to net.DefaultResolver
+	Dialer   Dialer   // Dialer for Domains certificate checks; defaults to a net.Dialer
+	// Revisions, when set, records the configuration of every service deployed
+	// through Client.Services so it can be restored with Services.Rollback.
+	Revisions RevisionStore
+	// OnRevisionError, when set, receives errors recording a revision. Such
+	// errors never fail the deploy.
+	OnRevisionError func(error)
 }
 
 // Client is the main entry point for the Easypanel SDK.
@@ -16,6 +24,7 @@ type Client struct {
 	Settings *SettingsService
 	Domains  *DomainsService
 	Actions  *ActionsService
+	Backups  *BackupsService
 
 	client *httpClient
 }
@@ -23,13 +32,16 @@ type Client struct {
 // New creates a new Easypanel client with the given configuration.
 func New(cfg Config) *Client {
 	c := newHTTPClient(cfg.Endpoint, cfg.Token)
+	domains := &DomainsService{client: c, resolver: cfg.Resolver, dialer: cfg.Dialer}
+	services := &ServicesService{client: c, domainsSvc: domains, revisions: cfg.Revisions, onRevisionError: cfg.OnRevisionError}
 	return &Client{
-		Projects: &ProjectsService{client: c},
-		Services: &ServicesService{client: c},
+		Projects: &ProjectsService{client: c, servSvc: services},
+		Services: services,
 		Monitor:  &MonitorService{client: c},
 		Settings: &SettingsService{client: c},
-		Domains:  &DomainsService{client: c},
+		Domains:  domains,
 		Actions:  &ActionsService{client: c},
+		Backups:  &BackupsService{client: c},
 		client:   c,
 	}
 }
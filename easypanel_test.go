@@ -22,6 +22,7 @@ func TestNew(t *testing.T) {
 	assert.NotNil(t, client.Monitor)
 	assert.NotNil(t, client.Settings)
 	assert.NotNil(t, client.Domains)
+	assert.NotNil(t, client.Backups)
 }
 
 func TestGetUser(t *testing.T) {
@@ -0,0 +1,197 @@
+package easypanel
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ServiceSpec is the desired configuration of a service for
+// Client.EnsureService. Nil fields are left as they are; a non-nil empty
+// slice clears the setting.
+type ServiceSpec struct {
+	// SelectService names the service. Image, Password and RootPassword are
+	// used when a database service has to be created.
+	SelectService
+	Type ServiceType
+
+	Source    *ServiceSource
+	Build     *BuildConfig
+	Env       *string
+	Mounts    []MountEntry
+	Ports     []PortParams
+	Resources *Resources
+	Deploy    *DeployParams // SelectService is ignored
+	Redirects []RedirectParams
+	BasicAuth []UserParams
+
+	// DeployOnChange deploys the service when anything was created or changed.
+	DeployOnChange bool
+}
+
+// EnsureProject creates the project if it does not exist. It reports whether
+// the project was created.
+func (c *Client) EnsureProject(ctx context.Context, name string) (bool, error) {
+	projects, err := c.Projects.List(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range projects.Result.Data.JSON {
+		if p.Name == name {
+			return false, nil
+		}
+	}
+	if _, err := c.Projects.Create(ctx, ProjectName{Name: name}); err != nil {
+		return false, fmt.Errorf("easypanel: ensure project %q: %w", name, err)
+	}
+	return true, nil
+}
+
+// EnsureService creates the service, and its project, if missing and then
+// converges every non-nil field of spec: a setting is only updated when the
+// inspected value differs. It reports whether anything was created or
+// updated, so running it again with the same spec reports false. Settings are
+// compared with what Inspect returns; a setting whose values the panel hides,
+// such as a registry password, is resent and reported as changed every time.
+func (c *Client) EnsureService(ctx context.Context, spec ServiceSpec) (bool, error) {
+	if spec.Type == "" {
+		return false, fmt.Errorf("easypanel: ensure service: type is required")
+	}
+	target := SelectService{ProjectName: spec.ProjectName, ServiceName: spec.ServiceName}
+	fail := func(step string, err error) (bool, error) {
+		return false, fmt.Errorf("easypanel: ensure %s/%s: %s: %w", target.ProjectName, target.ServiceName, step, err)
+	}
+
+	changed, err := c.EnsureProject(ctx, spec.ProjectName)
+	if err != nil {
+		return false, err
+	}
+	exists := false
+	if !changed {
+		project, err := c.Projects.Inspect(ctx, ProjectQuery{ProjectName: spec.ProjectName})
+		if err != nil {
+			return fail("inspect project", err)
+		}
+		for _, svc := range project.Result.Data.JSON.Services {
+			name := svc.name()
+			if name != spec.ServiceName {
+				continue
+			}
+			if svc.Type != spec.Type {
+				return fail("inspect project", fmt.Errorf("service exists with type %q, not %q", svc.Type, spec.Type))
+			}
+			exists = true
+		}
+	}
+
+	var current Service
+	if exists {
+		resp, err := c.Services.Inspect(ctx, spec.Type, target)
+		if err != nil {
+			return fail("inspect", err)
+		}
+		current = resp.Result.Data.JSON
+	} else {
+		create := CreateServiceParams{SelectService: spec.SelectService}
+		if _, err := c.Services.Create(ctx, spec.Type, create); err != nil {
+			return fail("create", err)
+		}
+		changed = true
+	}
+
+	st := spec.Type
+	steps := []struct {
+		name  string
+		want  bool
+		apply func() error
+	}{
+		{"source", spec.Source != nil && !sameSource(st, target, *spec.Source, current.Source), func() error {
+			params, err := sourceParams(st, target, *spec.Source, false)
+			if err != nil {
+				return err
+			}
+			return c.Services.applySource(ctx, st, params)
+		}},
+		{"build", spec.Build != nil && (current.Build == nil || *current.Build != *spec.Build), func() error {
+			return c.Services.UpdateBuild(ctx, st, UpdateBuildParams{SelectService: target, Build: spec.Build})
+		}},
+		{"env", spec.Env != nil && strings.TrimSpace(*spec.Env) != strings.TrimSpace(current.Env), func() error {
+			return c.Services.UpdateEnv(ctx, st, UpdateEnv{SelectService: target, Env: *spec.Env})
+		}},
+		{"mounts", spec.Mounts != nil && !sameSlice(spec.Mounts, current.Mounts), func() error {
+			return c.Services.UpdateMounts(ctx, st, MountParams{SelectService: target, Mounts: spec.Mounts})
+		}},
+		{"ports", spec.Ports != nil && !sameSlice(spec.Ports, current.Ports), func() error {
+			return c.Services.UpdatePorts(ctx, st, UpdatePorts{SelectService: target, Ports: spec.Ports})
+		}},
+		{"resources", spec.Resources != nil && *spec.Resources != current.Resources, func() error {
+			return c.Services.UpdateResources(ctx, st, UpdateResources{SelectService: target, Resources: *spec.Resources})
+		}},
+		{"deploy settings", spec.Deploy != nil && !sameDeploy(*spec.Deploy, current.Deploy), func() error {
+			deploy := *spec.Deploy
+			deploy.SelectService = target
+			return c.Services.UpdateDeploy(ctx, st, deploy)
+		}},
+		{"redirects", spec.Redirects != nil && !sameSlice(spec.Redirects, current.Redirects), func() error {
+			return c.Services.UpdateRedirects(ctx, st, UpdateRedirects{SelectService: target, Redirects: spec.Redirects})
+		}},
+		{"basic auth", spec.BasicAuth != nil && !sameSlice(spec.BasicAuth, current.BasicAuth), func() error {
+			return c.Services.UpdateBasicAuth(ctx, st, UpdateBasicAuth{SelectService: target, BasicAuth: spec.BasicAuth})
+		}},
+	}
+	for _, step := range steps {
+		if !step.want {
+			continue
+		}
+		if err := step.apply(); err != nil {
+			return fail(step.name, err)
+		}
+		changed = true
+	}
+
+	if changed && spec.DeployOnChange {
+		if err := c.Services.Deploy(ctx, st, target); err != nil {
+			return fail("deploy", err)
+		}
+	}
+	return changed, nil
+}
+
+// sameSource reports whether setting want would send the same update as the
+// one that produced current.
+func sameSource(st ServiceType, target SelectService, want ServiceSource, current *ServiceSource) bool {
+	if current == nil {
+		return false
+	}
+	wantParams, err := sourceParams(st, target, want, false)
+	if err != nil {
+		return false
+	}
+	currentParams, err := sourceParams(st, target, *current, false)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(wantParams, currentParams)
+}
+
+func sameDeploy(want DeployParams, current *DeployParams) bool {
+	if current == nil {
+		return false
+	}
+	return want.Replicas == current.Replicas &&
+		want.ZeroDowntime == current.ZeroDowntime &&
+		sameSlice(want.Command, current.Command) &&
+		sameSlice(want.CapAdd, current.CapAdd) &&
+		sameSlice(want.CapDrop, current.CapDrop) &&
+		sameSlice(want.Sysctls, current.Sysctls)
+}
+
+// sameSlice compares two slices element by element, treating nil and empty as
+// equal.
+func sameSlice[T any](a, b []T) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,108 @@
+package easypanel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ensureSpec() ServiceSpec {
+	env := "PORT=8080\nNODE_ENV=production"
+	return ServiceSpec{
+		SelectService: SelectService{ProjectName: "prod", ServiceName: "web"},
+		Type:          ServiceTypeApp,
+		Source:        &ServiceSource{DockerImageParams: DockerImageParams{Image: "nginx:1.27"}},
+		Env:           &env,
+		Ports:         []PortParams{{Protocol: "tcp", Published: 8080, Target: 80}},
+		Resources:     &Resources{CPULimit: 1, MemoryLimit: 512},
+		Deploy:        &DeployParams{Replicas: 2},
+		Mounts:        []MountEntry{},
+	}
+}
+
+func TestClientEnsureProject(t *testing.T) {
+	panel := newRecordingPanel(map[string]any{"projects.listProjects": []ProjectInfo{{Name: "prod"}}})
+	client := setupTestClient(t, panel.handler(t))
+
+	created, err := client.EnsureProject(context.Background(), "prod")
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	created, err = client.EnsureProject(context.Background(), "staging")
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, []string{"projects.createProject"}, panel.order)
+}
+
+func TestClientEnsureService_Create(t *testing.T) {
+	panel := newRecordingPanel(map[string]any{"projects.listProjects": []ProjectInfo{}})
+	client := setupTestClient(t, panel.handler(t))
+
+	spec := ensureSpec()
+	spec.DeployOnChange = true
+	changed, err := client.EnsureService(context.Background(), spec)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []string{
+		"projects.createProject",
+		"services.app.createService",
+		"services.app.updateSourceImage",
+		"services.app.updateEnv",
+		"services.app.updatePorts",
+		"services.app.updateResources",
+		"services.app.updateDeploy",
+		"services.app.deployService",
+	}, panel.order)
+}
+
+func TestClientEnsureService_Converged(t *testing.T) {
+	panel := newRecordingPanel(map[string]any{
+		"projects.listProjects":   []ProjectInfo{{Name: "prod"}},
+		"projects.inspectProject": ProjectInspect{Services: []Service{{Name: "web", Type: ServiceTypeApp}}},
+		"services.app.inspectService": Service{
+			Type:      ServiceTypeApp,
+			Source:    &ServiceSource{Type: "image", DockerImageParams: DockerImageParams{Image: "nginx:1.27"}},
+			Env:       "PORT=8080\nNODE_ENV=production\n",
+			Ports:     []PortParams{{Protocol: "tcp", Published: 8080, Target: 80}},
+			Resources: Resources{CPULimit: 1, MemoryLimit: 512},
+			Deploy:    &DeployParams{Replicas: 2, Command: []string{}},
+		},
+	})
+	client := setupTestClient(t, panel.handler(t))
+
+	spec := ensureSpec()
+	spec.DeployOnChange = true
+	changed, err := client.EnsureService(context.Background(), spec)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Empty(t, panel.order)
+
+	spec.Deploy = &DeployParams{Replicas: 3}
+	spec.Source.Image = "nginx:1.28"
+	changed, err = client.EnsureService(context.Background(), spec)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []string{"services.app.updateSourceImage", "services.app.updateDeploy", "services.app.deployService"}, panel.order)
+
+	var deploy DeployParams
+	panel.body(t, "services.app.updateDeploy", &deploy)
+	assert.Equal(t, SelectService{ProjectName: "prod", ServiceName: "web"}, deploy.SelectService)
+	assert.Equal(t, 3, deploy.Replicas)
+}
+
+func TestClientEnsureService_TypeMismatch(t *testing.T) {
+	panel := newRecordingPanel(map[string]any{
+		"projects.listProjects":   []ProjectInfo{{Name: "prod"}},
+		"projects.inspectProject": ProjectInspect{Services: []Service{{Name: "web", Type: ServiceTypeCompose}}},
+	})
+	client := setupTestClient(t, panel.handler(t))
+
+	_, err := client.EnsureService(context.Background(), ensureSpec())
+	assert.ErrorContains(t, err, `service exists with type "compose", not "app"`)
+	assert.Empty(t, panel.order)
+
+	_, err = client.EnsureService(context.Background(), ServiceSpec{SelectService: SelectService{ProjectName: "prod", ServiceName: "web"}})
+	assert.ErrorContains(t, err, "type is required")
+}
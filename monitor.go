@@ -1,6 +1,10 @@
 package easypanel
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"time"
+)
 
 // MonitorService handles monitoring-related API operations.
 type MonitorService struct {
@@ -34,3 +38,32 @@ func (s *MonitorService) GetSystemStats(ctx context.Context) (RestResponse[Syste
 	err := s.client.get(ctx, routeGetSystemStats, nil, &resp)
 	return resp, err
 }
+
+// WaitHealthy polls GetDockerTaskStats every interval until the service has at
+// least one desired task and all desired tasks are running. Use a context
+// deadline to bound the wait. A non-positive interval defaults to 5s.
+func (s *MonitorService) WaitHealthy(ctx context.Context, params SelectService, interval time.Duration) error {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	key := params.ProjectName + "_" + params.ServiceName
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var last TaskStatus
+	for {
+		resp, err := s.GetDockerTaskStats(ctx)
+		if err == nil {
+			last = resp.Result.Data.JSON[key]
+			if last.Desired > 0 && last.Actual >= last.Desired {
+				return nil
+			}
+		} else if ctx.Err() == nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("easypanel: %s not healthy, %d of %d tasks running: %w", key, last.Actual, last.Desired, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -97,6 +98,43 @@ func TestMonitorGetDockerTaskStats(t *testing.T) {
 	assert.Equal(t, 5, got["project_api"].Desired)
 }
 
+func TestMonitorWaitHealthy(t *testing.T) {
+	calls := 0
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		actual := 1
+		if calls >= 3 {
+			actual = 2
+		}
+		writeJSON(t, w, newRestResponse(DockerTaskStats{"prod_web": {Actual: actual, Desired: 2}}))
+	})
+
+	err := client.Monitor.WaitHealthy(context.Background(), SelectService{ProjectName: "prod", ServiceName: "web"}, time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestMonitorWaitHealthy_Timeout(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, newRestResponse(DockerTaskStats{}))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := client.Monitor.WaitHealthy(ctx, SelectService{ProjectName: "prod", ServiceName: "web"}, time.Millisecond)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "prod_web not healthy, 0 of 0 tasks running")
+}
+
+func TestMonitorWaitHealthy_ZeroInterval(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, newRestResponse(DockerTaskStats{"prod_web": {Actual: 1, Desired: 1}}))
+	})
+
+	err := client.Monitor.WaitHealthy(context.Background(), SelectService{ProjectName: "prod", ServiceName: "web"}, -time.Second)
+	assert.NoError(t, err)
+}
+
 func TestMonitorGetMonitorTableData(t *testing.T) {
 	want := []ContainerStats{
 		{
@@ -0,0 +1,203 @@
+package easypanel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MoveOptions controls ServicesService.Move.
+type MoveOptions struct {
+	// Type is the type of the service. When empty it is looked up in the
+	// source project.
+	Type ServiceType
+	// AllowDataLoss must be set to move a database or a service with volume
+	// mounts: volumes belong to the service, so the moved service starts with
+	// empty volumes and the original data is deleted with the original.
+	AllowDataLoss bool
+	// HealthTimeout bounds the wait for the new service to run all its tasks.
+	// Defaults to 5m.
+	HealthTimeout time.Duration
+	// PollInterval is how often task stats are checked. Defaults to 5s.
+	PollInterval time.Duration
+}
+
+// MoveResult describes what ServicesService.Move did.
+type MoveResult struct {
+	Type           ServiceType
+	Service        Service  // The new service as returned by Create
+	Domains        []Domain // Domains repointed at the new service
+	SkippedDomains []Domain // Domains that do not route to a service and were left alone
+	// SourceDestroyed is set once from has been destroyed. A Move failing
+	// with it set cannot be rolled back: to is the only copy left and may be
+	// missing the published ports of from.
+	SourceDestroyed bool
+}
+
+// Move recreates the service from as to, which may be in another project,
+// and removes from once to is running. The steps are:
+//
+//   - clone the inspected configuration to to, without domains and published
+//     ports, and deploy it
+//   - wait until Monitor.GetDockerTaskStats reports all desired tasks of to
+//     running
+//   - move the legacy domains of from to to and repoint the domains of from
+//     at to
+//   - destroy from
+//   - publish the original ports on to and deploy it again
+//
+// Published ports are applied last because the host ports are held by from
+// until it is destroyed. If Clone fails or to does not become healthy, to is
+// destroyed and from is left untouched. If moving the domains or destroying
+// from fails, the domains are pointed back at from and to is destroyed. This
+// cleanup runs even when ctx is canceled. Failures after from is destroyed
+// leave to in place, set SourceDestroyed and name the ports that were not
+// published in the error.
+func (s *ServicesService) Move(ctx context.Context, from, to SelectService, opts MoveOptions) (MoveResult, error) {
+	result := MoveResult{Type: opts.Type}
+	if from.ProjectName == to.ProjectName && from.ServiceName == to.ServiceName {
+		return result, fmt.Errorf("easypanel: move %s/%s: source and target are the same", from.ProjectName, from.ServiceName)
+	}
+	if opts.HealthTimeout <= 0 {
+		opts.HealthTimeout = 5 * time.Minute
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 5 * time.Second
+	}
+	if result.Type == "" {
+		st, err := s.lookupType(ctx, from)
+		if err != nil {
+			return result, err
+		}
+		result.Type = st
+	}
+	st := result.Type
+	fail := func(step string, err error) (MoveResult, error) {
+		return result, fmt.Errorf("easypanel: move %s/%s to %s/%s: %s: %w", from.ProjectName, from.ServiceName, to.ProjectName, to.ServiceName, step, err)
+	}
+
+	inspected, err := s.Inspect(ctx, st, SelectService{ProjectName: from.ProjectName, ServiceName: from.ServiceName})
+	if err != nil {
+		return fail("inspect", err)
+	}
+	src := inspected.Result.Data.JSON
+	if !opts.AllowDataLoss {
+		if st.IsDatabase() {
+			return fail("check volumes", fmt.Errorf("%s service data would be lost; set AllowDataLoss", st))
+		}
+		for _, m := range src.Mounts {
+			if m.Type == "volume" {
+				return fail("check volumes", fmt.Errorf("volume %q would be lost; set AllowDataLoss", m.Name))
+			}
+		}
+	}
+
+	target := SelectService{ProjectName: to.ProjectName, ServiceName: to.ServiceName}
+	source := SelectService{ProjectName: from.ProjectName, ServiceName: from.ServiceName}
+	cloned, err := s.Clone(ctx, from, to, CloneOptions{
+		Type:        st,
+		RewritePort: func(int) int { return 0 },
+		Deploy:      true,
+	})
+	result.Service = cloned.Service
+	if err != nil {
+		if cloned.Created {
+			cleanupCtx, cancel := cleanupContext(ctx)
+			defer cancel()
+			if derr := s.Destroy(cleanupCtx, st, target); derr != nil {
+				err = errors.Join(err, fmt.Errorf("destroy new service: %w", derr))
+			}
+		}
+		return result, err
+	}
+
+	healthCtx, cancel := context.WithTimeout(ctx, opts.HealthTimeout)
+	err = (&MonitorService{client: s.client}).WaitHealthy(healthCtx, target, opts.PollInterval)
+	cancel()
+	if err != nil {
+		cleanupCtx, cancel := cleanupContext(ctx)
+		defer cancel()
+		if derr := s.Destroy(cleanupCtx, st, target); derr != nil {
+			err = errors.Join(err, fmt.Errorf("destroy new service: %w", derr))
+		}
+		return fail("wait healthy", err)
+	}
+
+	// Until from is destroyed, a failure points the domains back at from and
+	// destroys to.
+	domains := s.domains()
+	var existing []Domain
+	legacyMoved := false
+	rollback := func(step string, err error) (MoveResult, error) {
+		cleanupCtx, cancel := cleanupContext(ctx)
+		defer cancel()
+		errs := []error{err}
+		if rerr := restoreDomains(cleanupCtx, domains, existing[:len(result.Domains)]); rerr != nil {
+			errs = append(errs, fmt.Errorf("switch back: %w", rerr))
+		}
+		result.Domains = nil
+		if legacyMoved {
+			if rerr := s.UpdateDomains(cleanupCtx, st, UpdateDomainsParams{SelectService: source, Domains: src.Domains}); rerr != nil {
+				errs = append(errs, fmt.Errorf("restore legacy domains: %w", rerr))
+			}
+		}
+		if derr := s.Destroy(cleanupCtx, st, target); derr != nil {
+			errs = append(errs, fmt.Errorf("destroy new service: %w", derr))
+		}
+		return fail(step, errors.Join(errs...))
+	}
+
+	if len(src.Domains) > 0 {
+		err := s.UpdateDomains(ctx, st, UpdateDomainsParams{SelectService: source, Domains: []DomainParams{}})
+		switch {
+		case errors.Is(err, ErrUnsupported):
+		case err != nil:
+			return rollback("domains", err)
+		default:
+			legacyMoved = true
+			if err := s.UpdateDomains(ctx, st, UpdateDomainsParams{SelectService: target, Domains: src.Domains}); err != nil {
+				return rollback("domains", err)
+			}
+		}
+	}
+	listed, err := domains.List(ctx, ListDomainsParams{ProjectName: from.ProjectName, ServiceName: from.ServiceName})
+	if err != nil && !errors.Is(err, ErrUnsupported) {
+		return rollback("list domains", err)
+	}
+	existing, result.SkippedDomains = splitServiceDomains(listed.Result.Data.JSON)
+	result.Domains, err = switchDomains(ctx, domains, existing, target)
+	if err != nil {
+		return rollback("domains", err)
+	}
+
+	if err := s.Destroy(ctx, st, source); err != nil {
+		return rollback("destroy original", err)
+	}
+	result.SourceDestroyed = true
+
+	if len(src.Ports) > 0 {
+		// from is gone, so there is nothing to roll back to: tell the caller
+		// which ports still have to be published on to.
+		unpublished := func(err error) error {
+			return fmt.Errorf("original service destroyed, ports %s not published on the new service: %w", formatPorts(src.Ports), err)
+		}
+		if err := s.UpdatePorts(ctx, st, UpdatePorts{SelectService: target, Ports: src.Ports}); err != nil {
+			return fail("ports", unpublished(err))
+		}
+		if err := s.Deploy(ctx, st, target); err != nil {
+			return fail("deploy", unpublished(err))
+		}
+	}
+	return result, nil
+}
+
+// formatPorts lists ports as "published:target/protocol".
+func formatPorts(ports []PortParams) string {
+	list := make([]string, len(ports))
+	for i, p := range ports {
+		list[i] = fmt.Sprintf("%d:%d/%s", p.Published, p.Target, p.Protocol)
+	}
+	return strings.Join(list, ", ")
+}
@@ -0,0 +1,202 @@
+package easypanel
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func moveSourceApp() Service {
+	src := cloneSourceApp()
+	src.Mounts = []MountEntry{{Type: "bind", HostPath: "/srv/shared", MountPath: "/shared"}}
+	src.Domains = []DomainParams{{Host: "legacy.example.com", HTTPS: true, Port: 80}}
+	return src
+}
+
+func TestServicesMove(t *testing.T) {
+	panel := newRecordingPanel(map[string]any{
+		"services.app.inspectService": moveSourceApp(),
+		"domains.listDomains":         []Domain{NewServiceDomain("prod", "web", "app.example.com", 80)},
+		"monitor.getDockerTaskStats":  DockerTaskStats{"prod_web": {Actual: 2, Desired: 2}, "apps_frontend": {Actual: 2, Desired: 2}},
+	})
+	client := setupTestClient(t, panel.handler(t))
+
+	result, err := client.Services.Move(context.Background(),
+		SelectService{ProjectName: "prod", ServiceName: "web"},
+		SelectService{ProjectName: "apps", ServiceName: "frontend"},
+		MoveOptions{Type: ServiceTypeApp, PollInterval: time.Millisecond})
+	require.NoError(t, err)
+	assert.True(t, result.SourceDestroyed)
+	target := SelectService{ProjectName: "apps", ServiceName: "frontend"}
+
+	require.Len(t, result.Domains, 1)
+	var domain Domain
+	panel.body(t, "domains.updateDomain", &domain)
+	assert.Equal(t, "app.example.com", domain.Host)
+	assert.Equal(t, result.Domains[0].ID, domain.ID, "the existing domain is updated in place")
+	assert.Equal(t, "apps", domain.ServiceDestination.ProjectName)
+	assert.Equal(t, "frontend", domain.ServiceDestination.ServiceName)
+	assert.NotContains(t, panel.order, "domains.createDomain")
+
+	var legacy UpdateDomainsParams
+	panel.body(t, "services.app.updateDomains", &legacy)
+	assert.Equal(t, target, legacy.SelectService)
+	assert.Equal(t, moveSourceApp().Domains, legacy.Domains)
+	legacyUpdates := 0
+	for _, proc := range panel.order {
+		if proc == "services.app.updateDomains" {
+			legacyUpdates++
+		}
+	}
+	assert.Equal(t, 2, legacyUpdates, "legacy domains are cleared on the original first")
+
+	var destroyed SelectService
+	panel.body(t, "services.app.destroyService", &destroyed)
+	assert.Equal(t, SelectService{ProjectName: "prod", ServiceName: "web"}, destroyed)
+
+	var ports UpdatePorts
+	panel.body(t, "services.app.updatePorts", &ports)
+	assert.Equal(t, target, ports.SelectService)
+	assert.Equal(t, moveSourceApp().Ports, ports.Ports)
+
+	index := func(proc string) int {
+		for i, p := range panel.order {
+			if p == proc {
+				return i
+			}
+		}
+		t.Fatalf("no POST to %s", proc)
+		return -1
+	}
+	assert.Less(t, index("services.app.deployService"), index("domains.updateDomain"))
+	assert.Less(t, index("domains.updateDomain"), index("services.app.destroyService"))
+	assert.Less(t, index("services.app.destroyService"), index("services.app.updatePorts"))
+	assert.Equal(t, "services.app.deployService", panel.order[len(panel.order)-1])
+}
+
+func TestServicesMove_Unhealthy(t *testing.T) {
+	panel := newRecordingPanel(map[string]any{
+		"services.app.inspectService": moveSourceApp(),
+		"monitor.getDockerTaskStats":  DockerTaskStats{"apps_frontend": {Actual: 0, Desired: 2}},
+	})
+	client := setupTestClient(t, panel.handler(t))
+
+	_, err := client.Services.Move(context.Background(),
+		SelectService{ProjectName: "prod", ServiceName: "web"},
+		SelectService{ProjectName: "apps", ServiceName: "frontend"},
+		MoveOptions{Type: ServiceTypeApp, HealthTimeout: 20 * time.Millisecond, PollInterval: time.Millisecond})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "wait healthy")
+
+	var destroyed SelectService
+	panel.body(t, "services.app.destroyService", &destroyed)
+	assert.Equal(t, SelectService{ProjectName: "apps", ServiceName: "frontend"}, destroyed, "only the new service is removed")
+	assert.NotContains(t, panel.order, "domains.updateDomain")
+	assert.NotContains(t, panel.order, "services.app.updateDomains")
+}
+
+func TestServicesMove_DataLoss(t *testing.T) {
+	panel := newRecordingPanel(map[string]any{
+		"services.app.inspectService":      cloneSourceApp(),
+		"services.postgres.inspectService": Service{Type: ServiceTypePostgres},
+	})
+	client := setupTestClient(t, panel.handler(t))
+	from := SelectService{ProjectName: "prod", ServiceName: "web"}
+	to := SelectService{ProjectName: "apps", ServiceName: "web"}
+
+	_, err := client.Services.Move(context.Background(), from, to, MoveOptions{Type: ServiceTypeApp})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `volume "data" would be lost`)
+
+	_, err = client.Services.Move(context.Background(), from, to, MoveOptions{Type: ServiceTypePostgres})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "postgres service data would be lost")
+	assert.Empty(t, panel.order)
+
+	_, err = client.Services.Move(context.Background(), from, from, MoveOptions{Type: ServiceTypeApp})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "source and target are the same")
+}
+
+func TestServicesMove_CloneFails(t *testing.T) {
+	panel := newRecordingPanel(map[string]any{"services.app.inspectService": moveSourceApp()})
+	panel.failPost = "services.app.updateEnv"
+	client := setupTestClient(t, panel.handler(t))
+
+	_, err := client.Services.Move(context.Background(),
+		SelectService{ProjectName: "prod", ServiceName: "web"},
+		SelectService{ProjectName: "apps", ServiceName: "frontend"},
+		MoveOptions{Type: ServiceTypeApp})
+	assert.ErrorContains(t, err, "env: rejected")
+
+	var destroyed SelectService
+	panel.body(t, "services.app.destroyService", &destroyed)
+	assert.Equal(t, SelectService{ProjectName: "apps", ServiceName: "frontend"}, destroyed, "the partial clone is removed")
+}
+
+func TestServicesMove_PortsFailAfterDestroy(t *testing.T) {
+	panel := newRecordingPanel(map[string]any{
+		"services.app.inspectService": moveSourceApp(),
+		"domains.listDomains":         []Domain{},
+		"monitor.getDockerTaskStats":  DockerTaskStats{"apps_frontend": {Actual: 1, Desired: 1}},
+	})
+	panel.failPost = "services.app.updatePorts"
+	client := setupTestClient(t, panel.handler(t))
+
+	result, err := client.Services.Move(context.Background(),
+		SelectService{ProjectName: "prod", ServiceName: "web"},
+		SelectService{ProjectName: "apps", ServiceName: "frontend"},
+		MoveOptions{Type: ServiceTypeApp, PollInterval: time.Millisecond})
+	assert.ErrorContains(t, err, "ports: original service destroyed, ports 8080:80/tcp, 9000:9000/udp not published on the new service")
+	assert.True(t, result.SourceDestroyed)
+	assert.Equal(t, 1, countPosts(panel, "services.app.destroyService"), "the new service is kept")
+}
+
+func TestServicesMove_RollsBack(t *testing.T) {
+	routed := NewServiceDomain("prod", "web", "app.example.com", 80)
+	panel := newRecordingPanel(map[string]any{
+		"services.app.inspectService": moveSourceApp(),
+		"domains.listDomains":         []Domain{routed},
+		"monitor.getDockerTaskStats":  DockerTaskStats{"apps_frontend": {Actual: 2, Desired: 2}},
+	})
+	from := SelectService{ProjectName: "prod", ServiceName: "web"}
+	to := SelectService{ProjectName: "apps", ServiceName: "frontend"}
+
+	var destroyed []SelectService
+	recorded := panel.handler(t)
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/trpc/services.app.destroyService" {
+			var sel SelectService
+			decodeTRPCBody(t, r, &sel)
+			destroyed = append(destroyed, sel)
+			if sel == from {
+				w.WriteHeader(http.StatusBadRequest)
+				writeJSON(t, w, Error{ErrorMessage: "busy"})
+				return
+			}
+			writeJSON(t, w, newRestResponse(sel))
+			return
+		}
+		recorded(w, r)
+	})
+
+	result, err := client.Services.Move(context.Background(), from, to, MoveOptions{Type: ServiceTypeApp, PollInterval: time.Millisecond})
+	assert.ErrorContains(t, err, "destroy original: busy")
+	assert.False(t, result.SourceDestroyed)
+	assert.Empty(t, result.Domains)
+	assert.Equal(t, []SelectService{from, to}, destroyed, "the new service is removed after a failed destroy of the original")
+
+	var domain Domain
+	panel.body(t, "domains.updateDomain", &domain)
+	assert.Equal(t, routed, domain, "the domain points at the original again")
+
+	var legacy UpdateDomainsParams
+	panel.body(t, "services.app.updateDomains", &legacy)
+	assert.Equal(t, from, legacy.SelectService)
+	assert.Equal(t, moveSourceApp().Domains, legacy.Domains, "legacy domains are given back to the original")
+	assert.NotContains(t, panel.order, "services.app.updatePorts")
+}
@@ -0,0 +1,100 @@
+package easypanel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Resolver looks up DNS records. *net.Resolver satisfies it.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+	LookupCNAME(ctx context.Context, host string) (string, error)
+}
+
+// DNSPreflight reports whether a host's DNS records point at the panel server.
+type DNSPreflight struct {
+	Host       string
+	ServerIP   string
+	LookupHost string   // Name actually resolved; a random subdomain for wildcard hosts
+	CNAME      string   // Canonical name, if the host is an alias
+	Addresses  []string // A and AAAA records
+	Mismatched []string // Addresses that do not belong to the panel server
+	Missing    bool     // No A or AAAA records exist
+	Problems   []string // Human-readable summary of everything that would break issuance
+}
+
+// OK reports whether no problems were found.
+func (p DNSPreflight) OK() bool {
+	return len(p.Problems) == 0
+}
+
+// Preflight resolves host and compares its A/AAAA records with the panel's server
+// IP, so that DNS mistakes surface before Let's Encrypt issuance fails. For a
+// wildcard host such as "*.example.com" a random subdomain is resolved to check
+// that a wildcard record covers it. DNS lookup failures are reported as problems;
+// the returned error is only set if the server IP cannot be fetched.
+func (s *DomainsService) Preflight(ctx context.Context, host string) (DNSPreflight, error) {
+	report := DNSPreflight{Host: host, LookupHost: host}
+
+	var ip RestResponse[string]
+	if err := s.client.get(ctx, routeGetServerIp, nil, &ip); err != nil {
+		return report, err
+	}
+	report.ServerIP = strings.TrimSpace(ip.Result.Data.JSON)
+
+	if strings.HasPrefix(host, "*.") {
+		report.LookupHost = wildcardProbeHost(host)
+	}
+
+	resolver := s.resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	if cname, err := resolver.LookupCNAME(ctx, report.LookupHost); err == nil {
+		cname = strings.TrimSuffix(cname, ".")
+		if !strings.EqualFold(cname, report.LookupHost) {
+			report.CNAME = cname
+		}
+	}
+
+	addrs, err := resolver.LookupIPAddr(ctx, report.LookupHost)
+	var dnsErr *net.DNSError
+	switch {
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound, err == nil && len(addrs) == 0:
+		report.Missing = true
+		if report.LookupHost != host {
+			report.Problems = append(report.Problems, fmt.Sprintf("no wildcard record covers %s", host))
+		} else {
+			report.Problems = append(report.Problems, fmt.Sprintf("%s has no A or AAAA records", host))
+		}
+		return report, nil
+	case err != nil:
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+		report.Problems = append(report.Problems, fmt.Sprintf("lookup %s: %v", report.LookupHost, err))
+		return report, nil
+	}
+
+	server := net.ParseIP(report.ServerIP)
+	for _, a := range addrs {
+		report.Addresses = append(report.Addresses, a.IP.String())
+		if server == nil || !a.IP.Equal(server) {
+			report.Mismatched = append(report.Mismatched, a.IP.String())
+		}
+	}
+	for _, m := range report.Mismatched {
+		report.Problems = append(report.Problems, fmt.Sprintf("%s resolves to %s, expected %s", report.LookupHost, m, report.ServerIP))
+	}
+	return report, nil
+}
+
+// wildcardProbeHost returns a random host covered by the wildcard host
+// "*.<domain>", used to probe DNS and certificates served for the wildcard.
+func wildcardProbeHost(host string) string {
+	return "probe-" + newDomainID()[:8] + strings.TrimPrefix(host, "*")
+}
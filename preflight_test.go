@@ -0,0 +1,99 @@
+package easypanel
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResolver answers lookups from fixed maps. Names under a key starting with
+// "*." match any subdomain.
+type fakeResolver struct {
+	ips    map[string][]string
+	cnames map[string]string
+}
+
+func (f fakeResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := f.ips[host]
+	if !ok {
+		if i := strings.Index(host, "."); i >= 0 {
+			ips, ok = f.ips["*"+host[i:]]
+		}
+	}
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	var addrs []net.IPAddr
+	for _, ip := range ips {
+		addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+	}
+	return addrs, nil
+}
+
+func (f fakeResolver) LookupCNAME(_ context.Context, host string) (string, error) {
+	if c, ok := f.cnames[host]; ok {
+		return c, nil
+	}
+	return host + ".", nil
+}
+
+func newPreflightClient(t *testing.T, r Resolver) *Client {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/trpc/settings.getServerIp", r.URL.Path)
+		writeJSON(t, w, newRestResponse("203.0.113.10"))
+	})
+	client.Domains.resolver = r
+	return client
+}
+
+func TestDomainsPreflight(t *testing.T) {
+	client := newPreflightClient(t, fakeResolver{
+		ips: map[string][]string{
+			"app.example.com":       {"203.0.113.10"},
+			"www.example.com":       {"203.0.113.10"},
+			"dual.example.com":      {"203.0.113.10", "2001:db8::1"},
+			"*.preview.example.com": {"203.0.113.10"},
+		},
+		cnames: map[string]string{"www.example.com": "app.example.com."},
+	})
+	ctx := context.Background()
+
+	ok, err := client.Domains.Preflight(ctx, "app.example.com")
+	require.NoError(t, err)
+	assert.True(t, ok.OK())
+	assert.Equal(t, "203.0.113.10", ok.ServerIP)
+	assert.Equal(t, []string{"203.0.113.10"}, ok.Addresses)
+	assert.Empty(t, ok.CNAME)
+
+	alias, err := client.Domains.Preflight(ctx, "www.example.com")
+	require.NoError(t, err)
+	assert.True(t, alias.OK())
+	assert.Equal(t, "app.example.com", alias.CNAME)
+
+	dual, err := client.Domains.Preflight(ctx, "dual.example.com")
+	require.NoError(t, err)
+	assert.False(t, dual.OK())
+	assert.Equal(t, []string{"2001:db8::1"}, dual.Mismatched)
+	assert.Contains(t, dual.Problems[0], "resolves to 2001:db8::1, expected 203.0.113.10")
+
+	missing, err := client.Domains.Preflight(ctx, "nope.example.com")
+	require.NoError(t, err)
+	assert.True(t, missing.Missing)
+	assert.Contains(t, missing.Problems[0], "has no A or AAAA records")
+
+	wildcard, err := client.Domains.Preflight(ctx, "*.preview.example.com")
+	require.NoError(t, err)
+	assert.True(t, wildcard.OK())
+	assert.True(t, strings.HasSuffix(wildcard.LookupHost, ".preview.example.com"))
+	assert.NotEqual(t, "*.preview.example.com", wildcard.LookupHost)
+
+	uncovered, err := client.Domains.Preflight(ctx, "*.example.com")
+	require.NoError(t, err)
+	assert.True(t, uncovered.Missing)
+	assert.Contains(t, uncovered.Problems[0], "no wildcard record covers *.example.com")
+}
@@ -0,0 +1,275 @@
+package easypanel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var previewProjectName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// PreviewOptions configures a PreviewManager.
+type PreviewOptions struct {
+	// Template is the project every preview is cloned from.
+	Template string
+	// Prefix marks preview projects; a preview with ID "42" lives in project
+	// Prefix+"42". Defaults to "preview-". Projects are never matched by
+	// anything other than this prefix, so it must not be shared with regular
+	// projects.
+	Prefix string
+	// DomainSuffix is passed to Projects.Clone as PreviewDomainSuffix.
+	DomainSuffix string
+	// BranchServices limits which services follow the preview branch. When
+	// empty, every service with a Git or GitHub source does.
+	BranchServices []string
+	// EnvReplacements and SkipSecrets are passed to Projects.Clone.
+	EnvReplacements map[string]string
+	SkipSecrets     bool
+}
+
+// Preview describes one preview environment.
+type Preview struct {
+	ID          string
+	ProjectName string
+	Branch      string // Branch of the first branch-following service, if any
+	CreatedAt   time.Time
+	Services    []string
+}
+
+// PreviewManager creates, updates and expires preview environments cloned from
+// a template project. All state lives in the panel: a preview is a project
+// whose name starts with the configured prefix, its age is the project's
+// creation time, its ID is the rest of the project name and its branch is
+// the one its services are built from.
+type PreviewManager struct {
+	client *Client
+	opts   PreviewOptions
+	now    func() time.Time
+}
+
+// NewPreviewManager returns a PreviewManager for the given options.
+func NewPreviewManager(client *Client, opts PreviewOptions) (*PreviewManager, error) {
+	if opts.Template == "" {
+		return nil, fmt.Errorf("easypanel: preview template project is required")
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = "preview-"
+	}
+	if !previewProjectName.MatchString(opts.Prefix) {
+		return nil, fmt.Errorf("easypanel: invalid preview prefix %q", opts.Prefix)
+	}
+	if strings.HasPrefix(opts.Template, opts.Prefix) {
+		return nil, fmt.Errorf("easypanel: template %q must not start with the preview prefix %q", opts.Template, opts.Prefix)
+	}
+	return &PreviewManager{client: client, opts: opts, now: time.Now}, nil
+}
+
+// ProjectName returns the project name of the preview with the given ID. IDs
+// are lowercased and characters a project name cannot contain become "-".
+// Ensure only accepts IDs this leaves unchanged, so that every preview maps
+// to its own project and List can return the original ID.
+func (m *PreviewManager) ProjectName(id string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(id) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	return m.opts.Prefix + b.String()
+}
+
+// Ensure makes the preview with the given ID run branch. A missing preview is
+// cloned from the template and all its non-database services are deployed.
+// An existing preview only has the services whose branch changed updated and
+// redeployed, so calling Ensure again for the same
+// branch restarts nothing. The returned bool reports whether the preview was
+// created. IDs may only contain lowercase letters, digits, "-" and "_".
+func (m *PreviewManager) Ensure(ctx context.Context, id, branch string) (Preview, bool, error) {
+	if id == "" || branch == "" {
+		return Preview{}, false, fmt.Errorf("easypanel: preview ID and branch are required")
+	}
+	project := m.ProjectName(id)
+	if project != m.opts.Prefix+id {
+		return Preview{}, false, fmt.Errorf("easypanel: invalid preview ID %q: use lowercase letters, digits, \"-\" or \"_\" (e.g. %q)", id, strings.TrimPrefix(project, m.opts.Prefix))
+	}
+
+	projects, err := m.client.Projects.List(ctx)
+	if err != nil {
+		return Preview{}, false, err
+	}
+	created := true
+	for _, p := range projects.Result.Data.JSON {
+		if p.Name == project {
+			created = false
+			break
+		}
+	}
+	if created {
+		_, err := m.client.Projects.Clone(ctx, m.opts.Template, project, ProjectCloneOptions{
+			EnvReplacements:     m.opts.EnvReplacements,
+			PreviewDomainSuffix: m.opts.DomainSuffix,
+			SkipSecrets:         m.opts.SkipSecrets,
+			DestroyOnError:      true,
+		})
+		if err != nil {
+			return Preview{}, false, fmt.Errorf("easypanel: create preview %q: %w", id, err)
+		}
+	}
+
+	inspected, err := m.client.Projects.Inspect(ctx, ProjectQuery{ProjectName: project})
+	if err != nil {
+		return Preview{}, created, err
+	}
+	info := inspected.Result.Data.JSON
+	for _, svc := range info.Services {
+		if svc.Type.IsDatabase() {
+			continue
+		}
+		name := svc.name()
+		sel := SelectService{ProjectName: project, ServiceName: name}
+		inspectedSvc, err := m.client.Services.Inspect(ctx, svc.Type, sel)
+		if err != nil {
+			return Preview{}, created, err
+		}
+		full := inspectedSvc.Result.Data.JSON
+		changed, err := m.setBranch(ctx, svc.Type, sel, full.Source, branch)
+		if err != nil {
+			return Preview{}, created, fmt.Errorf("easypanel: preview %q: set branch of %s: %w", id, name, err)
+		}
+		if !created && !changed {
+			continue
+		}
+		if err := m.client.Services.Deploy(ctx, svc.Type, sel); err != nil {
+			return Preview{}, created, fmt.Errorf("easypanel: preview %q: deploy %s: %w", id, name, err)
+		}
+	}
+
+	preview := m.preview(info.Project, info.Services)
+	preview.Branch = branch
+	return preview, created, nil
+}
+
+// setBranch points the Git source of a service at branch, if the service
+// follows the preview branch and is not on it already. It reports whether the
+// source was changed.
+func (m *PreviewManager) setBranch(ctx context.Context, st ServiceType, sel SelectService, src *ServiceSource, branch string) (bool, error) {
+	if src == nil || !m.followsBranch(sel.ServiceName) || sourceBranch(src) == branch {
+		return false, nil
+	}
+	var err error
+	switch {
+	case st == ServiceTypeCompose && src.GitParams.Repo != "" && src.ComposeContent == "":
+		err = m.client.Services.UpdateSourceGitCompose(ctx, st, UpdateSourceGitCompose{
+			ProjectName: sel.ProjectName,
+			ServiceName: sel.ServiceName,
+			Repo:        src.GitParams.Repo,
+			Ref:         branch,
+			RootPath:    src.GitParams.Path,
+			ComposeFile: src.ComposeFile,
+			AutoDeploy:  src.AutoDeploy,
+		})
+	case src.Owner != "":
+		gh := src.GithubParams
+		gh.Branch = branch
+		err = m.client.Services.UpdateSourceGithub(ctx, st, UpdateGithub{SelectService: sel, GithubParams: gh, AutoDeploy: src.AutoDeploy})
+	case src.GitParams.Repo != "":
+		git := src.GitParams
+		git.Branch = branch
+		err = m.client.Services.UpdateSourceGit(ctx, st, UpdateGit{SelectService: sel, GitParams: git, AutoDeploy: src.AutoDeploy})
+	default:
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// sourceBranch returns the branch of a GitHub or Git source.
+func sourceBranch(src *ServiceSource) string {
+	if src.Owner != "" {
+		return src.GithubParams.Branch
+	}
+	return src.GitParams.Branch
+}
+
+func (m *PreviewManager) followsBranch(service string) bool {
+	if len(m.opts.BranchServices) == 0 {
+		return true
+	}
+	for _, s := range m.opts.BranchServices {
+		if s == service {
+			return true
+		}
+	}
+	return false
+}
+
+// List returns the active previews, oldest first.
+func (m *PreviewManager) List(ctx context.Context) ([]Preview, error) {
+	resp, err := m.client.Projects.ListWithServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	all := resp.Result.Data.JSON
+
+	var previews []Preview
+	for _, p := range all.Projects {
+		if !strings.HasPrefix(p.Name, m.opts.Prefix) {
+			continue
+		}
+		var services []Service
+		for _, svc := range all.Services {
+			if svc.ProjectName == p.Name {
+				services = append(services, svc)
+			}
+		}
+		previews = append(previews, m.preview(p, services))
+	}
+	sort.SliceStable(previews, func(i, j int) bool { return previews[i].CreatedAt.Before(previews[j].CreatedAt) })
+	return previews, nil
+}
+
+// Expire tears down previews created more than ttl ago and returns the IDs of
+// those destroyed. Previews whose creation time cannot be parsed are kept.
+// A failure to destroy one preview does not stop the others; all failures are
+// returned together.
+func (m *PreviewManager) Expire(ctx context.Context, ttl time.Duration) ([]string, error) {
+	previews, err := m.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := m.now().Add(-ttl)
+
+	var expired []string
+	var errs []error
+	for _, p := range previews {
+		if p.CreatedAt.IsZero() || p.CreatedAt.After(cutoff) {
+			continue
+		}
+		if err := m.client.Projects.Destroy(ctx, ProjectName{Name: p.ProjectName}); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		expired = append(expired, p.ID)
+	}
+	return expired, errors.Join(errs...)
+}
+
+func (m *PreviewManager) preview(p ProjectInfo, services []Service) Preview {
+	preview := Preview{ID: strings.TrimPrefix(p.Name, m.opts.Prefix), ProjectName: p.Name}
+	if t, err := time.Parse(time.RFC3339, p.CreatedAt); err == nil {
+		preview.CreatedAt = t
+	}
+	for _, svc := range services {
+		name := svc.name()
+		preview.Services = append(preview.Services, name)
+		if preview.Branch == "" && svc.Source != nil && m.followsBranch(name) {
+			preview.Branch = sourceBranch(svc.Source)
+		}
+	}
+	return preview
+}
@@ -0,0 +1,147 @@
+package easypanel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func previewFixtures(existing []ProjectInfo) map[string]any {
+	return map[string]any{
+		"projects.listProjects": existing,
+		"projects.inspectProject": ProjectInspect{
+			Project: ProjectInfo{Name: "preview-pr-42", CreatedAt: "2026-10-01T12:00:00.000Z"},
+			Services: []Service{
+				{SelectService: SelectService{ServiceName: "web"}, Type: ServiceTypeApp},
+				{SelectService: SelectService{ServiceName: "db"}, Type: ServiceTypePostgres},
+			},
+		},
+		"services.app.inspectService": Service{
+			Type: ServiceTypeApp,
+			Source: &ServiceSource{
+				Type:         "github",
+				GithubParams: GithubParams{Owner: "acme", Repo: "web", Branch: "main"},
+			},
+		},
+		"services.postgres.inspectService": Service{Type: ServiceTypePostgres},
+		"domains.listDomains":              []Domain{},
+	}
+}
+
+func TestNewPreviewManager(t *testing.T) {
+	_, err := NewPreviewManager(nil, PreviewOptions{})
+	assert.ErrorContains(t, err, "template project is required")
+
+	_, err = NewPreviewManager(nil, PreviewOptions{Template: "preview-base"})
+	assert.ErrorContains(t, err, "must not start with the preview prefix")
+
+	_, err = NewPreviewManager(nil, PreviewOptions{Template: "prod", Prefix: "PR "})
+	assert.ErrorContains(t, err, "invalid preview prefix")
+
+	m, err := NewPreviewManager(nil, PreviewOptions{Template: "prod"})
+	require.NoError(t, err)
+	assert.Equal(t, "preview-pr-42", m.ProjectName("PR#42"))
+}
+
+func TestPreviewManagerEnsure_Create(t *testing.T) {
+	panel := newRecordingPanel(previewFixtures(nil))
+	client := setupTestClient(t, panel.handler(t))
+	m, err := NewPreviewManager(client, PreviewOptions{Template: "prod"})
+	require.NoError(t, err)
+
+	preview, created, err := m.Ensure(context.Background(), "pr-42", "feature/login")
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "pr-42", preview.ID)
+	assert.Equal(t, "feature/login", preview.Branch)
+	assert.Equal(t, []string{"web", "db"}, preview.Services)
+
+	var project ProjectName
+	panel.body(t, "projects.createProject", &project)
+	assert.Equal(t, "preview-pr-42", project.Name)
+
+	var github UpdateGithub
+	panel.body(t, "services.app.updateSourceGithub", &github)
+	assert.Equal(t, "feature/login", github.Branch)
+	assert.Equal(t, "preview-pr-42", github.ProjectName)
+	assert.Contains(t, panel.order, "services.app.deployService")
+	assert.NotContains(t, panel.order, "services.postgres.deployService")
+	assert.NotContains(t, panel.order, "services.app.updateEnv")
+}
+
+func TestPreviewManagerEnsure_InvalidID(t *testing.T) {
+	panel := newRecordingPanel(previewFixtures(nil))
+	client := setupTestClient(t, panel.handler(t))
+	m, err := NewPreviewManager(client, PreviewOptions{Template: "prod"})
+	require.NoError(t, err)
+
+	for _, id := range []string{"PR#42", "pr/42", "Pr-42"} {
+		_, _, err := m.Ensure(context.Background(), id, "main")
+		assert.ErrorContains(t, err, "invalid preview ID", id)
+	}
+	assert.Empty(t, panel.order)
+}
+
+func TestPreviewManagerEnsure_Update(t *testing.T) {
+	fixtures := previewFixtures([]ProjectInfo{{Name: "preview-pr-42"}})
+	panel := newRecordingPanel(fixtures)
+	client := setupTestClient(t, panel.handler(t))
+	m, err := NewPreviewManager(client, PreviewOptions{Template: "prod", BranchServices: []string{"web"}})
+	require.NoError(t, err)
+
+	_, created, err := m.Ensure(context.Background(), "pr-42", "fix")
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, []string{"services.app.updateSourceGithub", "services.app.deployService"}, panel.order)
+
+	panel.order = nil
+	_, _, err = m.Ensure(context.Background(), "pr-42", "main")
+	require.NoError(t, err)
+	assert.Empty(t, panel.order, "services already on the branch are not redeployed")
+}
+
+func TestPreviewManagerListAndExpire(t *testing.T) {
+	panel := newRecordingPanel(map[string]any{
+		"projects.listProjectsAndServices": ProjectsWithServices{
+			Projects: []ProjectInfo{
+				{Name: "prod", CreatedAt: "2025-01-01T00:00:00Z"},
+				{Name: "preview-new", CreatedAt: "2026-10-18T08:00:00.000Z"},
+				{Name: "preview-old", CreatedAt: "2026-10-10T08:00:00.000Z"},
+				{Name: "preview-odd", CreatedAt: "yesterday"},
+			},
+			Services: []Service{
+				{SelectService: SelectService{ProjectName: "preview-old", ServiceName: "web"}, Type: ServiceTypeApp,
+					Source: &ServiceSource{GitParams: GitParams{Repo: "https://git.example.com/web.git", Branch: "old-feature"}}},
+				{SelectService: SelectService{ProjectName: "preview-new", ServiceName: "web"}, Type: ServiceTypeApp},
+				{SelectService: SelectService{ProjectName: "prod", ServiceName: "web"}, Type: ServiceTypeApp},
+			},
+		},
+		"projects.inspectProject": ProjectInspect{Services: []Service{
+			{SelectService: SelectService{ServiceName: "web"}, Type: ServiceTypeApp},
+		}},
+	})
+	client := setupTestClient(t, panel.handler(t))
+	m, err := NewPreviewManager(client, PreviewOptions{Template: "prod"})
+	require.NoError(t, err)
+	m.now = func() time.Time { return time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC) }
+
+	previews, err := m.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, previews, 3)
+	assert.Equal(t, "odd", previews[0].ID, "unparsable creation times sort first")
+	assert.Equal(t, "old", previews[1].ID)
+	assert.Equal(t, "old-feature", previews[1].Branch)
+	assert.Equal(t, []string{"web"}, previews[1].Services)
+	assert.Equal(t, "new", previews[2].ID)
+
+	expired, err := m.Expire(context.Background(), 72*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old"}, expired)
+
+	var destroyed ProjectName
+	panel.body(t, "projects.destroyProject", &destroyed)
+	assert.Equal(t, "preview-old", destroyed.Name)
+}
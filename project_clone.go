@@ -0,0 +1,175 @@
+package easypanel
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ProjectCloneOptions controls ProjectsService.Clone.
+type ProjectCloneOptions struct {
+	// EnvReplacements are extra literal replacements applied to every cloned
+	// service's environment, on top of the automatic rewrite of internal
+	// hostnames ("<src>_<service>" becomes "<dst>_<service>").
+	EnvReplacements map[string]string
+	// PreviewDomainSuffix, when set, gives every service that has domains a
+	// generated host "<service>-<dst>.<suffix>"; further distinct hosts of the
+	// same service get "<service>-<n>-<dst>.<suffix>". The suffix needs a
+	// wildcard DNS record pointing at the panel. When empty no domains are
+	// cloned.
+	PreviewDomainSuffix string
+	// RewritePort is passed to ServicesService.Clone for every service.
+	RewritePort func(published int) int
+	// SkipSecrets is passed to ServicesService.Clone for every service.
+	SkipSecrets bool
+	// Deploy deploys each service once it is configured.
+	Deploy bool
+	// DestroyOnError tears the new project down if any service fails to clone.
+	DestroyOnError bool
+	// OnProgress, when set, is called before and after each service is cloned.
+	OnProgress func(ServiceProgress)
+}
+
+// ServiceProgress reports the progress of a multi-service operation.
+type ServiceProgress struct {
+	ProjectName string
+	ServiceName string
+	Type        ServiceType
+	Index       int // Zero-based position of the service in the operation
+	Total       int
+	Done        bool  // False when the service is about to be processed
+	Err         error // Set when Done and the service failed
+}
+
+// ServiceCloneResult is the outcome of cloning one service of a project.
+type ServiceCloneResult struct {
+	ServiceName string
+	CloneResult
+	Err error
+}
+
+// ProjectCloneReport summarizes ProjectsService.Clone.
+type ProjectCloneReport struct {
+	Source      string
+	Destination string
+	Services    []ServiceCloneResult
+	Destroyed   bool // The destination was torn down after a failure
+}
+
+// Clone creates project dst and clones every service of project src into it
+// with ServicesService.Clone. Databases are cloned first so that apps can
+// reach them on their first deploy. The first failing service stops the
+// clone; the report lists what was done up to that point. Use Destroy to
+// remove the destination again.
+func (s *ProjectsService) Clone(ctx context.Context, src, dst string, opts ProjectCloneOptions) (ProjectCloneReport, error) {
+	report := ProjectCloneReport{Source: src, Destination: dst}
+
+	inspected, err := s.Inspect(ctx, ProjectQuery{ProjectName: src})
+	if err != nil {
+		return report, fmt.Errorf("easypanel: clone project %q: %w", src, err)
+	}
+	services := sortServicesForClone(inspected.Result.Data.JSON.Services)
+
+	if _, err := s.Create(ctx, ProjectName{Name: dst}); err != nil {
+		return report, fmt.Errorf("easypanel: clone project %q: create %q: %w", src, dst, err)
+	}
+
+	rewriteEnv := projectEnvRewriter(src, dst, services, opts.EnvReplacements)
+	svcs := s.services()
+	for i, svc := range services {
+		name := svc.name()
+		progress := ServiceProgress{ProjectName: dst, ServiceName: name, Type: svc.Type, Index: i, Total: len(services)}
+		if opts.OnProgress != nil {
+			opts.OnProgress(progress)
+		}
+
+		cloneOpts := CloneOptions{
+			Type:        svc.Type,
+			RewritePort: opts.RewritePort,
+			SkipSecrets: opts.SkipSecrets,
+			RewriteEnv:  rewriteEnv,
+			Deploy:      opts.Deploy,
+		}
+		if opts.PreviewDomainSuffix != "" {
+			cloneOpts.RewriteDomain = previewDomains(name, dst, opts.PreviewDomainSuffix)
+		}
+		result, err := svcs.Clone(ctx, SelectService{ProjectName: src, ServiceName: name}, SelectService{ProjectName: dst, ServiceName: name}, cloneOpts)
+		report.Services = append(report.Services, ServiceCloneResult{ServiceName: name, CloneResult: result, Err: err})
+
+		progress.Done, progress.Err = true, err
+		if opts.OnProgress != nil {
+			opts.OnProgress(progress)
+		}
+		if err != nil {
+			if opts.DestroyOnError {
+				report.Destroyed = s.Destroy(ctx, ProjectName{Name: dst}) == nil
+			}
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// sortServicesForClone orders databases before other services, each group by
+// name.
+func sortServicesForClone(services []Service) []Service {
+	sorted := append([]Service(nil), services...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if di, dj := sorted[i].Type.IsDatabase(), sorted[j].Type.IsDatabase(); di != dj {
+			return di
+		}
+		return sorted[i].name() < sorted[j].name()
+	})
+	return sorted
+}
+
+// projectEnvRewriter rewrites internal hostnames of src's services to dst,
+// matching whole words only so that "src_db2" is left alone when only "db" is
+// cloned, and then applies the extra literal replacements.
+func projectEnvRewriter(src, dst string, services []Service, extra map[string]string) func(string) string {
+	hosts := make(map[string]string, len(services))
+	alternatives := make([]string, 0, len(services))
+	for _, svc := range services {
+		name := svc.name()
+		hosts[src+"_"+name] = dst + "_" + name
+		alternatives = append(alternatives, regexp.QuoteMeta(src+"_"+name))
+	}
+	var hostPattern *regexp.Regexp
+	if len(alternatives) > 0 {
+		hostPattern = regexp.MustCompile(`\b(?:` + strings.Join(alternatives, "|") + `)\b`)
+	}
+
+	args := make([]string, 0, 2*len(extra))
+	for _, old := range sortedKeys(extra) {
+		args = append(args, old, extra[old])
+	}
+	replacer := strings.NewReplacer(args...)
+
+	return func(env string) string {
+		if hostPattern != nil {
+			env = hostPattern.ReplaceAllStringFunc(env, func(host string) string { return hosts[host] })
+		}
+		return replacer.Replace(env)
+	}
+}
+
+// previewDomains returns a RewriteDomain function that maps each distinct
+// host of service to a generated preview host under suffix.
+func previewDomains(service, project, suffix string) func(string) string {
+	service = strings.ReplaceAll(service, "_", "-")
+	project = strings.ReplaceAll(project, "_", "-")
+	hosts := make(map[string]string)
+	return func(host string) string {
+		if preview, ok := hosts[host]; ok {
+			return preview
+		}
+		preview := fmt.Sprintf("%s-%s.%s", service, project, suffix)
+		if n := len(hosts); n > 0 {
+			preview = fmt.Sprintf("%s-%d-%s.%s", service, n+1, project, suffix)
+		}
+		hosts[host] = preview
+		return preview
+	}
+}
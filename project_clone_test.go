@@ -0,0 +1,110 @@
+package easypanel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func previewPanel() *recordingPanel {
+	return newRecordingPanel(map[string]any{
+		"projects.inspectProject": ProjectInspect{Services: []Service{
+			{SelectService: SelectService{ServiceName: "web"}, Type: ServiceTypeApp},
+			{SelectService: SelectService{ServiceName: "db"}, Type: ServiceTypePostgres},
+		}},
+		"services.app.inspectService": Service{
+			Type: ServiceTypeApp,
+			Env:  "DATABASE_URL=postgres://app:pw@prod_db:5432/app\nCACHE=prod_db2:6379\nAPI=https://api.example.com",
+			Source: &ServiceSource{
+				Type:              "image",
+				DockerImageParams: DockerImageParams{Image: "nginx"},
+			},
+		},
+		"services.postgres.inspectService": Service{SelectService: SelectService{Image: "postgres:16", Password: "pw"}, Type: ServiceTypePostgres},
+		"domains.listDomains": []Domain{
+			NewServiceDomain("prod", "web", "example.com", 80),
+			NewServiceDomain("prod", "web", "www.example.com", 80),
+		},
+	})
+}
+
+func TestProjectsClone(t *testing.T) {
+	panel := previewPanel()
+	client := setupTestClient(t, panel.handler(t))
+
+	var progress []ServiceProgress
+	report, err := client.Projects.Clone(context.Background(), "prod", "pr_42", ProjectCloneOptions{
+		EnvReplacements:     map[string]string{"https://api.example.com": "https://api.staging.example.com"},
+		PreviewDomainSuffix: "preview.example.com",
+		OnProgress:          func(p ServiceProgress) { progress = append(progress, p) },
+	})
+	require.NoError(t, err)
+
+	var project ProjectName
+	panel.body(t, "projects.createProject", &project)
+	assert.Equal(t, "pr_42", project.Name)
+
+	require.Len(t, report.Services, 2)
+	assert.Equal(t, "db", report.Services[0].ServiceName, "databases are cloned first")
+	assert.Equal(t, "web", report.Services[1].ServiceName)
+	assert.Equal(t, []ServiceProgress{
+		{ProjectName: "pr_42", ServiceName: "db", Type: ServiceTypePostgres, Index: 0, Total: 2},
+		{ProjectName: "pr_42", ServiceName: "db", Type: ServiceTypePostgres, Index: 0, Total: 2, Done: true},
+		{ProjectName: "pr_42", ServiceName: "web", Type: ServiceTypeApp, Index: 1, Total: 2},
+		{ProjectName: "pr_42", ServiceName: "web", Type: ServiceTypeApp, Index: 1, Total: 2, Done: true},
+	}, progress)
+
+	var env UpdateEnv
+	panel.body(t, "services.app.updateEnv", &env)
+	assert.Equal(t, "DATABASE_URL=postgres://app:pw@pr_42_db:5432/app\nCACHE=prod_db2:6379\nAPI=https://api.staging.example.com", env.Env)
+
+	hosts := make([]string, 0, 2)
+	for _, d := range report.Services[1].Domains {
+		hosts = append(hosts, d.Host)
+		assert.Equal(t, "pr_42", d.ServiceDestination.ProjectName)
+	}
+	assert.Equal(t, []string{"web-pr-42.preview.example.com", "web-2-pr-42.preview.example.com"}, hosts)
+}
+
+func TestProjectsClone_DestroyOnError(t *testing.T) {
+	panel := previewPanel()
+	panel.failPost = "services.app.updateSourceImage"
+	client := setupTestClient(t, panel.handler(t))
+
+	report, err := client.Projects.Clone(context.Background(), "prod", "pr_42", ProjectCloneOptions{DestroyOnError: true})
+	assert.ErrorContains(t, err, "source: rejected")
+	require.Len(t, report.Services, 2)
+	assert.NoError(t, report.Services[0].Err)
+	assert.Error(t, report.Services[1].Err)
+	assert.True(t, report.Destroyed)
+	assert.Contains(t, panel.order, "services.app.destroyService")
+	assert.Contains(t, panel.order, "services.postgres.destroyService")
+	assert.Equal(t, "projects.destroyProject", panel.order[len(panel.order)-1])
+}
+
+func TestProjectsDestroy_ServiceFailureKeepsProject(t *testing.T) {
+	panel := previewPanel()
+	panel.failPost = "services.app.destroyService"
+	client := setupTestClient(t, panel.handler(t))
+
+	err := client.Projects.Destroy(context.Background(), ProjectName{Name: "pr_42"})
+	assert.ErrorContains(t, err, `destroy project "pr_42": web: `)
+	assert.Equal(t, []string{"services.app.destroyService", "services.postgres.destroyService"}, panel.order, "apps are destroyed before databases")
+}
+
+func TestProjectsTeardown_Progress(t *testing.T) {
+	panel := previewPanel()
+	client := setupTestClient(t, panel.handler(t))
+
+	var events []ServiceProgress
+	err := client.Projects.Teardown(context.Background(), "pr_42", func(p ServiceProgress) { events = append(events, p) })
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	assert.Equal(t, "web", events[0].ServiceName)
+	assert.False(t, events[0].Done)
+	assert.True(t, events[1].Done)
+	assert.Equal(t, "db", events[3].ServiceName)
+	assert.Equal(t, 2, events[3].Total)
+}
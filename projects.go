@@ -1,10 +1,24 @@
 package easypanel
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+)
 
 // ProjectsService handles project-related API operations.
 type ProjectsService struct {
-	client *httpClient
+	client  *httpClient
+	servSvc *ServicesService
+}
+
+// services returns the client's ServicesService, so service calls made on
+// behalf of projects share its revision store and DomainsService.
+func (s *ProjectsService) services() *ServicesService {
+	if s.servSvc != nil {
+		return s.servSvc
+	}
+	return &ServicesService{client: s.client}
 }
 
 // CanCreate checks if a new project can be created.
@@ -21,9 +35,44 @@ func (s *ProjectsService) Create(ctx context.Context, params ProjectName) (RestR
 	return resp, err
 }
 
-// Destroy deletes a project.
+// Destroy deletes a project. Its services are destroyed first, apps before
+// databases. Services that fail to be destroyed do not stop the others, but
+// are named in the returned error and the project itself is kept.
 func (s *ProjectsService) Destroy(ctx context.Context, params ProjectName) error {
-	return s.client.post(ctx, routeDestroyProject, params, nil)
+	return s.Teardown(ctx, params.Name, nil)
+}
+
+// Teardown destroys a project like Destroy, calling onProgress, if not nil,
+// before and after each service is destroyed.
+func (s *ProjectsService) Teardown(ctx context.Context, name string, onProgress func(ServiceProgress)) error {
+	inspected, err := s.Inspect(ctx, ProjectQuery{ProjectName: name})
+	if err != nil {
+		return fmt.Errorf("easypanel: destroy project %q: %w", name, err)
+	}
+	services := sortServicesForClone(inspected.Result.Data.JSON.Services)
+
+	svcs := s.services()
+	var errs []error
+	for i := len(services) - 1; i >= 0; i-- {
+		svc := services[i]
+		svcName := svc.name()
+		progress := ServiceProgress{ProjectName: name, ServiceName: svcName, Type: svc.Type, Index: len(services) - 1 - i, Total: len(services)}
+		if onProgress != nil {
+			onProgress(progress)
+		}
+		err := svcs.Destroy(ctx, svc.Type, SelectService{ProjectName: name, ServiceName: svcName})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", svcName, err))
+		}
+		progress.Done, progress.Err = true, err
+		if onProgress != nil {
+			onProgress(progress)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("easypanel: destroy project %q: %w", name, err)
+	}
+	return s.client.post(ctx, routeDestroyProject, ProjectName{Name: name}, nil)
 }
 
 // Inspect returns detailed information about a project including its services.
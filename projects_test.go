@@ -49,6 +49,10 @@ func TestProjectsCreate(t *testing.T) {
 
 func TestProjectsDestroy(t *testing.T) {
 	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/trpc/projects.inspectProject" {
+			writeJSON(t, w, newRestResponse(ProjectInspect{Project: ProjectInfo{Name: "test-project"}}))
+			return
+		}
 		assert.Equal(t, http.MethodPost, r.Method)
 		assert.Equal(t, "/api/trpc/projects.destroyProject", r.URL.Path)
 
@@ -0,0 +1,134 @@
+package easypanel
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pathTail matches the rest of a URL after a host or path prefix: nothing, or a
+// path, query or fragment. It keeps "/old" from matching "/older".
+const pathTail = `((?:[/?#].*)?)$`
+
+// RedirectWWWToApex returns a rule redirecting www.<domain> to <domain>,
+// preserving scheme, path and query.
+func RedirectWWWToApex(domain string, permanent bool) RedirectParams {
+	return RedirectParams{
+		Name:        "www to apex",
+		Enabled:     true,
+		Regex:       `^(https?)://www\.` + regexp.QuoteMeta(domain) + pathTail,
+		Replacement: "${1}://" + domain + "${2}",
+		Permanent:   permanent,
+	}
+}
+
+// RedirectApexToWWW returns a rule redirecting <domain> to www.<domain>,
+// preserving scheme, path and query.
+func RedirectApexToWWW(domain string, permanent bool) RedirectParams {
+	return RedirectParams{
+		Name:        "apex to www",
+		Enabled:     true,
+		Regex:       `^(https?)://` + regexp.QuoteMeta(domain) + pathTail,
+		Replacement: "${1}://www." + domain + "${2}",
+		Permanent:   permanent,
+	}
+}
+
+// RedirectToHTTPS returns a permanent rule redirecting plain HTTP requests for
+// host to HTTPS, preserving path and query.
+func RedirectToHTTPS(host string) RedirectParams {
+	return RedirectParams{
+		Name:        "http to https",
+		Enabled:     true,
+		Regex:       `^http://` + regexp.QuoteMeta(host) + pathTail,
+		Replacement: "https://" + host + "${1}",
+		Permanent:   true,
+	}
+}
+
+// RedirectPathPrefix returns a rule moving everything under the path prefix
+// from to the prefix to on the same host, e.g. /blog/post → /news/post.
+func RedirectPathPrefix(host, from, to string, permanent bool) RedirectParams {
+	from = strings.TrimSuffix(from, "/")
+	to = strings.TrimSuffix(to, "/")
+	return RedirectParams{
+		Name:        fmt.Sprintf("%s to %s", from, to),
+		Enabled:     true,
+		Regex:       `^(https?)://` + regexp.QuoteMeta(host) + regexp.QuoteMeta(from) + pathTail,
+		Replacement: "${1}://" + host + to + "${2}",
+		Permanent:   permanent,
+	}
+}
+
+// replacementRef matches $1, ${1} and ${name} references in a replacement.
+var replacementRef = regexp.MustCompile(`\$(\d+|\{\w+\})`)
+
+// Validate checks that the regex compiles and that the replacement only
+// references groups the regex defines.
+func (r RedirectParams) Validate() error {
+	re, err := regexp.Compile(r.Regex)
+	if err != nil {
+		return fmt.Errorf("easypanel: redirect %q: invalid regex: %w", r.Name, err)
+	}
+	for _, m := range replacementRef.FindAllStringSubmatch(r.Replacement, -1) {
+		ref := strings.Trim(m[1], "{}")
+		if n, err := strconv.Atoi(ref); err == nil {
+			if n > re.NumSubexp() {
+				return fmt.Errorf("easypanel: redirect %q: replacement references group %d but regex has %d", r.Name, n, re.NumSubexp())
+			}
+		} else if re.SubexpIndex(ref) < 0 {
+			return fmt.Errorf("easypanel: redirect %q: replacement references unknown group %q", r.Name, ref)
+		}
+	}
+	return nil
+}
+
+// RedirectResult is the outcome of evaluating redirect rules against a URL.
+type RedirectResult struct {
+	Matched    bool
+	Rule       RedirectParams // The rule that matched
+	Location   string         // Redirect target
+	StatusCode int            // 301 for permanent rules, 302 otherwise
+}
+
+// EvaluateRedirects applies rules to rawURL the way Traefik's redirectRegex
+// middleware does: the first enabled rule whose regex matches the full request
+// URL (scheme, host, path and query) produces the redirect. Disabled rules are
+// skipped. An error is returned for the first rule that does not validate.
+func EvaluateRedirects(rules []RedirectParams, rawURL string) (RedirectResult, error) {
+	for _, r := range rules {
+		if !r.Enabled {
+			continue
+		}
+		if err := r.Validate(); err != nil {
+			return RedirectResult{}, err
+		}
+		re := regexp.MustCompile(r.Regex)
+		if !re.MatchString(rawURL) {
+			continue
+		}
+		status := http.StatusFound
+		if r.Permanent {
+			status = http.StatusMovedPermanently
+		}
+		return RedirectResult{
+			Matched:    true,
+			Rule:       r,
+			Location:   re.ReplaceAllString(rawURL, r.Replacement),
+			StatusCode: status,
+		}, nil
+	}
+	return RedirectResult{}, nil
+}
+
+// validateRedirects validates every rule and joins the errors.
+func validateRedirects(rules []RedirectParams) error {
+	var errs []error
+	for _, r := range rules {
+		errs = append(errs, r.Validate())
+	}
+	return errors.Join(errs...)
+}
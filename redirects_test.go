@@ -0,0 +1,93 @@
+package easypanel
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedirectBuilders(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     RedirectParams
+		url      string
+		location string
+		matched  bool
+	}{
+		{"www to apex", RedirectWWWToApex("example.com", true), "https://www.example.com/a/b?x=1", "https://example.com/a/b?x=1", true},
+		{"www to apex keeps scheme", RedirectWWWToApex("example.com", true), "http://www.example.com", "http://example.com", true},
+		{"www to apex ignores apex", RedirectWWWToApex("example.com", true), "https://example.com/", "", false},
+		{"www to apex ignores lookalike", RedirectWWWToApex("example.com", true), "https://www.example.com.evil.io/", "", false},
+		{"apex to www", RedirectApexToWWW("example.com", false), "https://example.com/docs", "https://www.example.com/docs", true},
+		{"apex to www ignores www", RedirectApexToWWW("example.com", false), "https://www.example.com/docs", "", false},
+		{"to https", RedirectToHTTPS("app.example.com"), "http://app.example.com/login?next=/home", "https://app.example.com/login?next=/home", true},
+		{"to https ignores https", RedirectToHTTPS("app.example.com"), "https://app.example.com/login", "", false},
+		{"path prefix", RedirectPathPrefix("example.com", "/blog/", "/news", true), "https://example.com/blog/post-1", "https://example.com/news/post-1", true},
+		{"path prefix exact", RedirectPathPrefix("example.com", "/blog", "/news", true), "https://example.com/blog", "https://example.com/news", true},
+		{"path prefix query", RedirectPathPrefix("example.com", "/blog", "/news", true), "https://example.com/blog?page=2", "https://example.com/news?page=2", true},
+		{"path prefix boundary", RedirectPathPrefix("example.com", "/blog", "/news", true), "https://example.com/blogger", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.rule.Validate())
+			got, err := EvaluateRedirects([]RedirectParams{tt.rule}, tt.url)
+			require.NoError(t, err)
+			assert.Equal(t, tt.matched, got.Matched)
+			assert.Equal(t, tt.location, got.Location)
+		})
+	}
+}
+
+func TestEvaluateRedirects(t *testing.T) {
+	disabled := RedirectToHTTPS("example.com")
+	disabled.Enabled = false
+	rules := []RedirectParams{
+		disabled,
+		RedirectWWWToApex("example.com", false),
+		RedirectPathPrefix("example.com", "/old", "/new", true),
+	}
+
+	got, err := EvaluateRedirects(rules, "http://example.com/old/page")
+	require.NoError(t, err)
+	assert.True(t, got.Matched)
+	assert.Equal(t, "http://example.com/new/page", got.Location)
+	assert.Equal(t, http.StatusMovedPermanently, got.StatusCode)
+	assert.Equal(t, "/old to /new", got.Rule.Name)
+
+	got, err = EvaluateRedirects(rules, "http://www.example.com/old/page")
+	require.NoError(t, err)
+	assert.Equal(t, "http://example.com/old/page", got.Location, "first matching rule wins")
+	assert.Equal(t, http.StatusFound, got.StatusCode)
+}
+
+func TestRedirectValidate(t *testing.T) {
+	bad := RedirectParams{Name: "bad", Enabled: true, Regex: "^(https?://", Replacement: "x"}
+	assert.ErrorContains(t, bad.Validate(), "invalid regex")
+
+	missingGroup := RedirectParams{Name: "missing", Enabled: true, Regex: "^https://(a)/", Replacement: "https://b/${2}"}
+	assert.ErrorContains(t, missingGroup.Validate(), "references group 2 but regex has 1")
+
+	unknownName := RedirectParams{Name: "named", Enabled: true, Regex: "^https://(?P<host>[^/]+)/", Replacement: "https://${path}"}
+	assert.ErrorContains(t, unknownName.Validate(), `unknown group "path"`)
+
+	named := RedirectParams{Name: "named", Enabled: true, Regex: "^https://(?P<host>[^/]+)/", Replacement: "https://www.${host}/"}
+	assert.NoError(t, named.Validate())
+
+	_, err := EvaluateRedirects([]RedirectParams{bad}, "https://example.com")
+	assert.Error(t, err)
+}
+
+func TestServicesUpdateRedirects_ValidatesLocally(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("no request expected")
+	})
+
+	err := client.Services.UpdateRedirects(context.Background(), ServiceTypeApp, UpdateRedirects{
+		SelectService: SelectService{ProjectName: "proj", ServiceName: "web"},
+		Redirects:     []RedirectParams{{Name: "broken", Regex: "(", Replacement: ""}},
+	})
+	assert.ErrorContains(t, err, `redirect "broken": invalid regex`)
+}
@@ -0,0 +1,164 @@
+package easypanel
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// memoryUnits maps memory suffixes to their size in bytes.
+var memoryUnits = []struct {
+	suffix string
+	bytes  float64
+}{
+	{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40},
+	{"K", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12},
+}
+
+// ParseCPU parses a CPU quantity into cores. It accepts plain or fractional
+// core counts ("2", "1.5") and millicores ("500m").
+func ParseCPU(quantity string) (float64, error) {
+	s := strings.TrimSpace(quantity)
+	scale := 1.0
+	if strings.HasSuffix(s, "m") {
+		s, scale = strings.TrimSuffix(s, "m"), 0.001
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("easypanel: invalid CPU quantity %q", quantity)
+	}
+	return v * scale, nil
+}
+
+// FormatCPU formats cores as a CPU quantity, using millicores below one core.
+func FormatCPU(cores float64) string {
+	if cores > 0 && cores < 1 {
+		return strconv.FormatFloat(cores*1000, 'f', -1, 64) + "m"
+	}
+	return strconv.FormatFloat(cores, 'f', -1, 64)
+}
+
+// ParseMemory parses a memory quantity into megabytes (MiB). It accepts binary
+// suffixes ("512Mi", "2Gi"), decimal suffixes ("2G", "500M") and plain numbers,
+// which are taken to already be in MiB.
+func ParseMemory(quantity string) (float64, error) {
+	s := strings.TrimSpace(quantity)
+	bytesPer := float64(1 << 20)
+	for _, u := range memoryUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			s, bytesPer = strings.TrimSuffix(s, u.suffix), u.bytes
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("easypanel: invalid memory quantity %q", quantity)
+	}
+	return v * bytesPer / (1 << 20), nil
+}
+
+// FormatMemory formats megabytes (MiB) as a memory quantity, using Gi when the
+// value is a whole number of gibibytes.
+func FormatMemory(mb float64) string {
+	if mb >= 1024 && mb == float64(int64(mb/1024))*1024 {
+		return strconv.FormatInt(int64(mb/1024), 10) + "Gi"
+	}
+	return strconv.FormatFloat(mb, 'f', -1, 64) + "Mi"
+}
+
+// ResourceSpec is a human-friendly form of Resources. Empty fields mean zero.
+type ResourceSpec struct {
+	CPULimit          string // e.g. "2" or "1500m"
+	CPUReservation    string
+	MemoryLimit       string // e.g. "1Gi" or "512Mi"
+	MemoryReservation string
+}
+
+// Resources parses the spec and validates the result.
+func (r ResourceSpec) Resources() (Resources, error) {
+	var res Resources
+	var err error
+	parse := func(s string, fn func(string) (float64, error), dst *float64) {
+		if err != nil || s == "" {
+			return
+		}
+		*dst, err = fn(s)
+	}
+	parse(r.CPULimit, ParseCPU, &res.CPULimit)
+	parse(r.CPUReservation, ParseCPU, &res.CPUReservation)
+	parse(r.MemoryLimit, ParseMemory, &res.MemoryLimit)
+	parse(r.MemoryReservation, ParseMemory, &res.MemoryReservation)
+	if err != nil {
+		return Resources{}, err
+	}
+	return res, res.Validate()
+}
+
+// Validate checks that no value is negative and that reservations do not
+// exceed their limits when a limit is set.
+func (r Resources) Validate() error {
+	if r.CPULimit < 0 || r.CPUReservation < 0 || r.MemoryLimit < 0 || r.MemoryReservation < 0 {
+		return fmt.Errorf("easypanel: resource values must not be negative")
+	}
+	if r.CPULimit > 0 && r.CPUReservation > r.CPULimit {
+		return fmt.Errorf("easypanel: CPU reservation %s exceeds limit %s", FormatCPU(r.CPUReservation), FormatCPU(r.CPULimit))
+	}
+	if r.MemoryLimit > 0 && r.MemoryReservation > r.MemoryLimit {
+		return fmt.Errorf("easypanel: memory reservation %s exceeds limit %s", FormatMemory(r.MemoryReservation), FormatMemory(r.MemoryLimit))
+	}
+	return nil
+}
+
+// CapacityReport compares requested resources with the host's capacity.
+type CapacityReport struct {
+	HostCPUs         float64
+	HostMemoryMB     float64
+	ReservedCPU      float64 // Sum of CPU reservations, including the request
+	ReservedMemoryMB float64 // Sum of memory reservations, including the request
+	Warnings         []string
+}
+
+// CheckCapacity compares r, the intended resources of target, with the host's
+// CPU count and memory from GetSystemStats. Reservations of every other service
+// are added to r's to detect overcommitment. Warnings are informational; the
+// panel does not reject overcommitted services.
+func (s *MonitorService) CheckCapacity(ctx context.Context, target SelectService, r Resources) (CapacityReport, error) {
+	var report CapacityReport
+
+	stats, err := s.GetSystemStats(ctx)
+	if err != nil {
+		return report, err
+	}
+	report.HostCPUs = float64(stats.Result.Data.JSON.CPUInfo.Count)
+	report.HostMemoryMB = stats.Result.Data.JSON.MemInfo.TotalMemMb
+
+	var all RestResponse[ProjectsWithServices]
+	if err := s.client.get(ctx, routeListProjectsAndServices, nil, &all); err != nil {
+		return report, err
+	}
+	report.ReservedCPU, report.ReservedMemoryMB = r.CPUReservation, r.MemoryReservation
+	for _, svc := range all.Result.Data.JSON.Services {
+		name := svc.name()
+		if svc.ProjectName == target.ProjectName && name == target.ServiceName {
+			continue
+		}
+		report.ReservedCPU += svc.Resources.CPUReservation
+		report.ReservedMemoryMB += svc.Resources.MemoryReservation
+	}
+
+	if r.CPULimit > report.HostCPUs {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("CPU limit %s exceeds host CPUs %s", FormatCPU(r.CPULimit), FormatCPU(report.HostCPUs)))
+	}
+	if r.MemoryLimit > report.HostMemoryMB {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("memory limit %s exceeds host memory %s", FormatMemory(r.MemoryLimit), FormatMemory(report.HostMemoryMB)))
+	}
+	if report.ReservedCPU > report.HostCPUs {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("total CPU reservations %s exceed host CPUs %s", FormatCPU(report.ReservedCPU), FormatCPU(report.HostCPUs)))
+	}
+	if report.ReservedMemoryMB > report.HostMemoryMB {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("total memory reservations %s exceed host memory %s", FormatMemory(report.ReservedMemoryMB), FormatMemory(report.HostMemoryMB)))
+	}
+	return report, nil
+}
@@ -0,0 +1,119 @@
+package easypanel
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCPU(t *testing.T) {
+	tests := map[string]float64{"500m": 0.5, "1.5": 1.5, "2": 2, " 250m ": 0.25, "0": 0}
+	for in, want := range tests {
+		got, err := ParseCPU(in)
+		require.NoError(t, err, in)
+		assert.InDelta(t, want, got, 1e-9, in)
+	}
+	for _, in := range []string{"", "abc", "-1", "1.5Gi", "NaN", "Inf", "infinity", "-Inf"} {
+		_, err := ParseCPU(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestParseMemory(t *testing.T) {
+	tests := map[string]float64{
+		"512Mi": 512,
+		"2Gi":   2048,
+		"1Ti":   1024 * 1024,
+		"2048":  2048,
+		"2G":    2e9 / (1 << 20),
+		"500M":  5e8 / (1 << 20),
+		"512Ki": 0.5,
+	}
+	for in, want := range tests {
+		got, err := ParseMemory(in)
+		require.NoError(t, err, in)
+		assert.InDelta(t, want, got, 1e-9, in)
+	}
+	for _, in := range []string{"", "lots", "-1Gi", "1.5m", "NaN", "Inf", "infinity", "InfGi"} {
+		_, err := ParseMemory(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestFormatUnits(t *testing.T) {
+	assert.Equal(t, "500m", FormatCPU(0.5))
+	assert.Equal(t, "1.5", FormatCPU(1.5))
+	assert.Equal(t, "0", FormatCPU(0))
+	assert.Equal(t, "512Mi", FormatMemory(512))
+	assert.Equal(t, "2Gi", FormatMemory(2048))
+	assert.Equal(t, "1536Mi", FormatMemory(1536))
+}
+
+func TestResourceSpec(t *testing.T) {
+	res, err := ResourceSpec{CPULimit: "2", CPUReservation: "500m", MemoryLimit: "1Gi", MemoryReservation: "512Mi"}.Resources()
+	require.NoError(t, err)
+	assert.Equal(t, Resources{CPULimit: 2, CPUReservation: 0.5, MemoryLimit: 1024, MemoryReservation: 512}, res)
+
+	res, err = ResourceSpec{MemoryReservation: "256Mi"}.Resources()
+	require.NoError(t, err, "reservation without limit is allowed")
+	assert.Equal(t, 256.0, res.MemoryReservation)
+
+	_, err = ResourceSpec{CPULimit: "500m", CPUReservation: "1"}.Resources()
+	assert.ErrorContains(t, err, "CPU reservation 1 exceeds limit 500m")
+
+	_, err = ResourceSpec{MemoryLimit: "512Mi", MemoryReservation: "1Gi"}.Resources()
+	assert.ErrorContains(t, err, "memory reservation 1Gi exceeds limit 512Mi")
+
+	_, err = ResourceSpec{MemoryLimit: "big"}.Resources()
+	assert.ErrorContains(t, err, "invalid memory quantity")
+}
+
+func TestMonitorCheckCapacity(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/trpc/monitor.getSystemStats":
+			writeJSON(t, w, newRestResponse(SystemStats{
+				CPUInfo: CPUInfo{Count: 4},
+				MemInfo: MemInfo{TotalMemMb: 8192},
+			}))
+		case "/api/trpc/projects.listProjectsAndServices":
+			writeJSON(t, w, newRestResponse(ProjectsWithServices{Services: []Service{
+				{SelectService: SelectService{ProjectName: "proj", ServiceName: "db"}, Resources: Resources{CPUReservation: 2, MemoryReservation: 4096}},
+				{SelectService: SelectService{ProjectName: "proj", ServiceName: "web"}, Resources: Resources{CPUReservation: 3, MemoryReservation: 4096}},
+			}}))
+		default:
+			t.Fatalf("unexpected request %s", r.URL.Path)
+		}
+	})
+	target := SelectService{ProjectName: "proj", ServiceName: "web"}
+
+	report, err := client.Monitor.CheckCapacity(context.Background(), target, Resources{CPULimit: 2, CPUReservation: 1, MemoryLimit: 2048, MemoryReservation: 1024})
+	require.NoError(t, err)
+	assert.Equal(t, 4.0, report.HostCPUs)
+	assert.Equal(t, 3.0, report.ReservedCPU, "the target's current reservation is replaced")
+	assert.Equal(t, 5120.0, report.ReservedMemoryMB)
+	assert.Empty(t, report.Warnings)
+
+	report, err = client.Monitor.CheckCapacity(context.Background(), target, Resources{CPULimit: 8, CPUReservation: 3, MemoryLimit: 16384, MemoryReservation: 8192})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"CPU limit 8 exceeds host CPUs 4",
+		"memory limit 16Gi exceeds host memory 8Gi",
+		"total CPU reservations 5 exceed host CPUs 4",
+		"total memory reservations 12Gi exceed host memory 8Gi",
+	}, report.Warnings)
+}
+
+func TestServicesUpdateResources_ValidatesLocally(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("no request expected")
+	})
+
+	err := client.Services.UpdateResources(context.Background(), ServiceTypeApp, UpdateResources{
+		Resources: Resources{MemoryLimit: 256, MemoryReservation: 512},
+	})
+	assert.ErrorContains(t, err, "exceeds limit")
+}
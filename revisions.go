@@ -0,0 +1,373 @@
+package easypanel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Revision is a snapshot of the deployable configuration of a service, taken
+// before it is deployed through Client.Services while Config.Revisions is set.
+// A deploy whose configuration matches the latest revision adds none.
+type Revision struct {
+	Number      int         `json:"number"` // Starts at 1 for each service
+	ProjectName string      `json:"projectName"`
+	ServiceName string      `json:"serviceName"`
+	Type        ServiceType `json:"type"`
+	// Image is the image reference, including its tag, of an image source.
+	Image string `json:"image,omitempty"`
+	// Ref is the branch or commit of a Git or GitHub source. The panel does
+	// not report the commit a branch resolved to, so rolling back to a branch
+	// deploys its current head; point sources at commits to pin them.
+	Ref       string         `json:"ref,omitempty"`
+	Env       string         `json:"env"`
+	Source    *ServiceSource `json:"source,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+// ErrRevisionNotFound is returned by RevisionStore.Get for unknown revisions.
+var ErrRevisionNotFound = errors.New("easypanel: revision not found")
+
+// RevisionStore keeps the revision history of services. Implementations must
+// be safe for concurrent use.
+type RevisionStore interface {
+	// Append stores rev as the next revision of its service and returns it
+	// with Number, and CreatedAt if zero, filled in.
+	Append(rev Revision) (Revision, error)
+	// List returns the revisions of svc, oldest first.
+	List(svc SelectService) ([]Revision, error)
+	// Get returns revision number of svc or ErrRevisionNotFound.
+	Get(svc SelectService, number int) (Revision, error)
+}
+
+// MemoryRevisionStore is a RevisionStore that keeps revisions in memory.
+type MemoryRevisionStore struct {
+	mu        sync.Mutex
+	revisions map[SelectService][]Revision
+	now       func() time.Time
+}
+
+// NewMemoryRevisionStore returns an empty MemoryRevisionStore.
+func NewMemoryRevisionStore() *MemoryRevisionStore {
+	return &MemoryRevisionStore{revisions: make(map[SelectService][]Revision), now: time.Now}
+}
+
+// Append implements RevisionStore.
+func (m *MemoryRevisionStore) Append(rev Revision) (Revision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return appendRevision(m.revisions, rev, m.now), nil
+}
+
+// List implements RevisionStore.
+func (m *MemoryRevisionStore) List(svc SelectService) ([]Revision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Revision(nil), m.revisions[revisionKey(svc)]...), nil
+}
+
+// Get implements RevisionStore.
+func (m *MemoryRevisionStore) Get(svc SelectService, number int) (Revision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return findRevision(m.revisions[revisionKey(svc)], number)
+}
+
+// FileRevisionStore is a RevisionStore that keeps revisions in a JSON file.
+// The file holds environment values, which often include secrets, and is
+// written with mode 0600. Processes sharing a file must not write to it
+// concurrently.
+type FileRevisionStore struct {
+	mu   sync.Mutex
+	path string
+	now  func() time.Time
+}
+
+// NewFileRevisionStore returns a FileRevisionStore backed by path. The file is
+// created on the first Append.
+func NewFileRevisionStore(path string) *FileRevisionStore {
+	return &FileRevisionStore{path: path, now: time.Now}
+}
+
+type revisionFile struct {
+	Services []revisionFileEntry `json:"services"`
+}
+
+type revisionFileEntry struct {
+	SelectService
+	Revisions []Revision `json:"revisions"`
+}
+
+// Append implements RevisionStore.
+func (f *FileRevisionStore) Append(rev Revision) (Revision, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	revisions, err := f.load()
+	if err != nil {
+		return rev, err
+	}
+	rev = appendRevision(revisions, rev, f.now)
+	return rev, f.save(revisions)
+}
+
+// List implements RevisionStore.
+func (f *FileRevisionStore) List(svc SelectService) ([]Revision, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	revisions, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+	return revisions[revisionKey(svc)], nil
+}
+
+// Get implements RevisionStore.
+func (f *FileRevisionStore) Get(svc SelectService, number int) (Revision, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	revisions, err := f.load()
+	if err != nil {
+		return Revision{}, err
+	}
+	return findRevision(revisions[revisionKey(svc)], number)
+}
+
+func (f *FileRevisionStore) load() (map[SelectService][]Revision, error) {
+	revisions := make(map[SelectService][]Revision)
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return revisions, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("easypanel: read revisions: %w", err)
+	}
+	var file revisionFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("easypanel: decode revisions %s: %w", f.path, err)
+	}
+	for _, e := range file.Services {
+		revisions[revisionKey(e.SelectService)] = e.Revisions
+	}
+	return revisions, nil
+}
+
+// save writes revisions to a temporary file and renames it over the store so
+// a failed write never leaves a truncated history.
+func (f *FileRevisionStore) save(revisions map[SelectService][]Revision) error {
+	var file revisionFile
+	for _, key := range sortedServiceKeys(revisions) {
+		file.Services = append(file.Services, revisionFileEntry{SelectService: key, Revisions: revisions[key]})
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("easypanel: encode revisions: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return fmt.Errorf("easypanel: write revisions: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("easypanel: write revisions: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("easypanel: write revisions: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("easypanel: write revisions: %w", err)
+	}
+	return nil
+}
+
+// revisionKey reduces svc to the fields revisions are keyed on.
+func revisionKey(svc SelectService) SelectService {
+	return SelectService{ProjectName: svc.ProjectName, ServiceName: svc.ServiceName}
+}
+
+func appendRevision(revisions map[SelectService][]Revision, rev Revision, now func() time.Time) Revision {
+	key := SelectService{ProjectName: rev.ProjectName, ServiceName: rev.ServiceName}
+	history := revisions[key]
+	rev.Number = 1
+	if len(history) > 0 {
+		rev.Number = history[len(history)-1].Number + 1
+	}
+	if rev.CreatedAt.IsZero() {
+		rev.CreatedAt = now()
+	}
+	revisions[key] = append(history, rev)
+	return rev
+}
+
+func findRevision(history []Revision, number int) (Revision, error) {
+	for _, rev := range history {
+		if rev.Number == number {
+			return rev, nil
+		}
+	}
+	return Revision{}, ErrRevisionNotFound
+}
+
+func sortedServiceKeys(revisions map[SelectService][]Revision) []SelectService {
+	keys := make([]SelectService, 0, len(revisions))
+	for key := range revisions {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].ProjectName != keys[j].ProjectName {
+			return keys[i].ProjectName < keys[j].ProjectName
+		}
+		return keys[i].ServiceName < keys[j].ServiceName
+	})
+	return keys
+}
+
+// newRevision snapshots the deployable configuration of an inspected service.
+func newRevision(st ServiceType, svc SelectService, inspected Service) Revision {
+	rev := Revision{
+		ProjectName: svc.ProjectName,
+		ServiceName: svc.ServiceName,
+		Type:        st,
+		Env:         inspected.Env,
+		Source:      inspected.Source,
+	}
+	if src := inspected.Source; src != nil {
+		rev.Image = src.Image
+		rev.Ref = src.GitParams.Branch
+	}
+	return rev
+}
+
+// sameConfig reports whether two revisions hold the same configuration.
+func (r Revision) sameConfig(other Revision) bool {
+	return r.Type == other.Type && r.Image == other.Image && r.Ref == other.Ref &&
+		r.Env == other.Env && reflect.DeepEqual(r.Source, other.Source)
+}
+
+// recordRevision inspects svc and appends its configuration to the revision
+// store unless it matches the latest revision. It does nothing when no store
+// is configured. Failures are passed to Config.OnRevisionError rather than
+// returned, so they never fail the deploy being recorded.
+func (s *ServicesService) recordRevision(ctx context.Context, st ServiceType, svc SelectService) {
+	if s.revisions == nil {
+		return
+	}
+	if err := s.appendRevision(ctx, st, revisionKey(svc)); err != nil && s.onRevisionError != nil {
+		s.onRevisionError(fmt.Errorf("easypanel: record revision of %s/%s: %w", svc.ProjectName, svc.ServiceName, err))
+	}
+}
+
+func (s *ServicesService) appendRevision(ctx context.Context, st ServiceType, key SelectService) error {
+	current, err := s.currentRevision(ctx, st, key)
+	if err != nil {
+		return err
+	}
+	history, err := s.revisions.List(key)
+	if err != nil {
+		return err
+	}
+	if n := len(history); n > 0 && history[n-1].sameConfig(current) {
+		return nil
+	}
+	_, err = s.revisions.Append(current)
+	return err
+}
+
+// currentRevision returns the configuration svc has now as an unnumbered
+// revision.
+func (s *ServicesService) currentRevision(ctx context.Context, st ServiceType, key SelectService) (Revision, error) {
+	inspected, err := s.Inspect(ctx, st, key)
+	if err != nil {
+		return Revision{}, err
+	}
+	return newRevision(st, key, inspected.Result.Data.JSON), nil
+}
+
+// Revisions returns the recorded revisions of svc, oldest first.
+func (s *ServicesService) Revisions(svc SelectService) ([]Revision, error) {
+	if s.revisions == nil {
+		return nil, fmt.Errorf("easypanel: no revision store configured")
+	}
+	return s.revisions.List(revisionKey(svc))
+}
+
+// Rollback re-applies the source and environment recorded in revision
+// toRevision of svc and deploys it. A toRevision of 0 steps back one revision
+// from the configuration the service has now: it picks the newest revision
+// that differs from the current configuration and is older than the newest
+// revision matching it. Rollbacks are not recorded as new revisions, so
+// repeated calls with 0 keep stepping back. The returned revision is the one
+// rolled back to.
+func (s *ServicesService) Rollback(ctx context.Context, st ServiceType, svc SelectService, toRevision int) (Revision, error) {
+	if s.revisions == nil {
+		return Revision{}, fmt.Errorf("easypanel: rollback needs Config.Revisions")
+	}
+	key := revisionKey(svc)
+	fail := func(step string, err error) (Revision, error) {
+		return Revision{}, fmt.Errorf("easypanel: rollback %s/%s: %s: %w", svc.ProjectName, svc.ServiceName, step, err)
+	}
+
+	var rev Revision
+	if toRevision == 0 {
+		history, err := s.revisions.List(key)
+		if err != nil {
+			return fail("list revisions", err)
+		}
+		current, err := s.currentRevision(ctx, st, key)
+		if err != nil {
+			return fail("inspect", err)
+		}
+		var ok bool
+		if rev, ok = previousRevision(history, current); !ok {
+			return fail("list revisions", fmt.Errorf("no previous revision"))
+		}
+	} else {
+		var err error
+		if rev, err = s.revisions.Get(key, toRevision); err != nil {
+			return fail(fmt.Sprintf("revision %d", toRevision), err)
+		}
+	}
+
+	if rev.Source != nil {
+		params, err := sourceParams(st, key, *rev.Source, false)
+		if err != nil {
+			return fail("source", err)
+		}
+		if err := s.applySource(ctx, st, params); err != nil {
+			return fail("source", err)
+		}
+	}
+	if err := s.UpdateEnv(ctx, st, UpdateEnv{SelectService: key, Env: rev.Env}); err != nil {
+		return fail("env", err)
+	}
+	if err := s.client.post(ctx, serviceRoute(routeDeployService, st), key, nil); err != nil {
+		return fail("deploy", err)
+	}
+	return rev, nil
+}
+
+// previousRevision finds the revision Rollback with 0 restores: the newest
+// revision that differs from current, searching from just before the newest
+// revision that matches current, or from the end if none does.
+func previousRevision(history []Revision, current Revision) (Revision, bool) {
+	start := len(history) - 1
+	for i := start; i >= 0; i-- {
+		if history[i].sameConfig(current) {
+			start = i - 1
+			break
+		}
+	}
+	for i := start; i >= 0; i-- {
+		if !history[i].sameConfig(current) {
+			return history[i], true
+		}
+	}
+	return Revision{}, false
+}
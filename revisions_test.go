@@ -0,0 +1,196 @@
+package easypanel
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func imageService(image, env string) Service {
+	return Service{
+		SelectService: SelectService{ProjectName: "prod", ServiceName: "web"},
+		Type:          ServiceTypeApp,
+		Env:           env,
+		Source:        &ServiceSource{Type: "image", DockerImageParams: DockerImageParams{Image: image}},
+	}
+}
+
+func TestServicesDeploy_RecordsRevision(t *testing.T) {
+	panel := newRecordingPanel(map[string]any{"services.app.inspectService": imageService("ghcr.io/acme/web:1.2", "A=1")})
+	client := setupTestClient(t, panel.handler(t))
+	client.Services.revisions = NewMemoryRevisionStore()
+	sel := SelectService{ProjectName: "prod", ServiceName: "web"}
+
+	require.NoError(t, client.Services.Deploy(context.Background(), ServiceTypeApp, sel))
+	require.NoError(t, client.Services.Deploy(context.Background(), ServiceTypeApp, sel))
+	revs, err := client.Services.Revisions(sel)
+	require.NoError(t, err)
+	require.Len(t, revs, 1, "redeploying the same configuration adds no revision")
+	assert.Equal(t, 1, revs[0].Number)
+	assert.Equal(t, "ghcr.io/acme/web:1.2", revs[0].Image)
+	assert.Equal(t, "A=1", revs[0].Env)
+	assert.Equal(t, ServiceTypeApp, revs[0].Type)
+	assert.False(t, revs[0].CreatedAt.IsZero())
+
+	panel.gets["services.app.inspectService"] = imageService("ghcr.io/acme/web:1.3", "A=1")
+	require.NoError(t, client.Services.Deploy(context.Background(), ServiceTypeApp, sel))
+	revs, err = client.Services.Revisions(sel)
+	require.NoError(t, err)
+	require.Len(t, revs, 2)
+	assert.Equal(t, 2, revs[1].Number)
+	assert.Equal(t, "ghcr.io/acme/web:1.3", revs[1].Image)
+}
+
+type failingRevisionStore struct{ MemoryRevisionStore }
+
+func (*failingRevisionStore) Append(Revision) (Revision, error) {
+	return Revision{}, errors.New("disk full")
+}
+
+func TestServicesDeploy_RevisionErrorDoesNotFailDeploy(t *testing.T) {
+	panel := newRecordingPanel(map[string]any{"services.app.inspectService": imageService("web:1", "")})
+	client := setupTestClient(t, panel.handler(t))
+	client.Services.revisions = &failingRevisionStore{}
+	var reported error
+	client.Services.onRevisionError = func(err error) { reported = err }
+
+	require.NoError(t, client.Services.Deploy(context.Background(), ServiceTypeApp, SelectService{ProjectName: "prod", ServiceName: "web"}))
+	assert.ErrorContains(t, reported, "record revision of prod/web: disk full")
+	assert.Equal(t, []string{"services.app.deployService"}, panel.order)
+}
+
+func TestServicesDeploy_GitRef(t *testing.T) {
+	svc := imageService("", "")
+	svc.Source = &ServiceSource{Type: "git", GitParams: GitParams{Repo: "https://git.example.com/web.git", Branch: "3f2c1ab"}}
+	panel := newRecordingPanel(map[string]any{"services.app.inspectService": svc})
+	client := setupTestClient(t, panel.handler(t))
+	client.Services.revisions = NewMemoryRevisionStore()
+
+	require.NoError(t, client.Services.Deploy(context.Background(), ServiceTypeApp, SelectService{ProjectName: "prod", ServiceName: "web"}))
+	revs, err := client.Services.Revisions(SelectService{ProjectName: "prod", ServiceName: "web"})
+	require.NoError(t, err)
+	require.Len(t, revs, 1)
+	assert.Equal(t, "3f2c1ab", revs[0].Ref)
+}
+
+func TestServicesRollback(t *testing.T) {
+	panel := newRecordingPanel(map[string]any{"services.app.inspectService": imageService("ghcr.io/acme/web:3", "A=3")})
+	client := setupTestClient(t, panel.handler(t))
+	store := NewMemoryRevisionStore()
+	client.Services.revisions = store
+	sel := SelectService{ProjectName: "prod", ServiceName: "web"}
+
+	for _, v := range []string{"1", "2", "3"} {
+		_, err := store.Append(newRevision(ServiceTypeApp, sel, imageService("ghcr.io/acme/web:"+v, "A="+v)))
+		require.NoError(t, err)
+	}
+
+	rev, err := client.Services.Rollback(context.Background(), ServiceTypeApp, sel, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rev.Number)
+
+	var image UpdateImage
+	panel.body(t, "services.app.updateSourceImage", &image)
+	assert.Equal(t, "ghcr.io/acme/web:2", image.Image)
+	var env UpdateEnv
+	panel.body(t, "services.app.updateEnv", &env)
+	assert.Equal(t, "A=2", env.Env)
+	assert.Equal(t, []string{"services.app.updateSourceImage", "services.app.updateEnv", "services.app.deployService"}, panel.order)
+
+	revs, err := store.List(sel)
+	require.NoError(t, err)
+	assert.Len(t, revs, 3, "a rollback records no revision")
+
+	// The panel now runs revision 2; rolling back again steps further back.
+	panel.gets["services.app.inspectService"] = imageService("ghcr.io/acme/web:2", "A=2")
+	rev, err = client.Services.Rollback(context.Background(), ServiceTypeApp, sel, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rev.Number)
+
+	panel.gets["services.app.inspectService"] = imageService("ghcr.io/acme/web:1", "A=1")
+	_, err = client.Services.Rollback(context.Background(), ServiceTypeApp, sel, 0)
+	assert.ErrorContains(t, err, "no previous revision")
+
+	rev, err = client.Services.Rollback(context.Background(), ServiceTypeApp, sel, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, rev.Number)
+	panel.body(t, "services.app.updateSourceImage", &image)
+	assert.Equal(t, "ghcr.io/acme/web:3", image.Image)
+}
+
+func TestServicesRollback_UnrecordedConfig(t *testing.T) {
+	panel := newRecordingPanel(map[string]any{"services.app.inspectService": imageService("web:hotfix", "")})
+	client := setupTestClient(t, panel.handler(t))
+	store := NewMemoryRevisionStore()
+	client.Services.revisions = store
+	sel := SelectService{ProjectName: "prod", ServiceName: "web"}
+	_, err := store.Append(newRevision(ServiceTypeApp, sel, imageService("web:1", "")))
+	require.NoError(t, err)
+
+	rev, err := client.Services.Rollback(context.Background(), ServiceTypeApp, sel, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "web:1", rev.Image, "a configuration changed outside Deploy rolls back to the latest revision")
+}
+
+func TestServicesRollback_Errors(t *testing.T) {
+	panel := newRecordingPanel(nil)
+	client := setupTestClient(t, panel.handler(t))
+	sel := SelectService{ProjectName: "prod", ServiceName: "web"}
+
+	_, err := client.Services.Rollback(context.Background(), ServiceTypeApp, sel, 0)
+	assert.ErrorContains(t, err, "rollback needs Config.Revisions")
+
+	store := NewMemoryRevisionStore()
+	client.Services.revisions = store
+	_, err = store.Append(newRevision(ServiceTypeApp, sel, imageService("web:1", "")))
+	require.NoError(t, err)
+
+	_, err = client.Services.Rollback(context.Background(), ServiceTypeApp, sel, 7)
+	assert.ErrorIs(t, err, ErrRevisionNotFound)
+	assert.Empty(t, panel.order)
+}
+
+func TestFileRevisionStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revisions.json")
+	store := NewFileRevisionStore(path)
+	store.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+	web := SelectService{ProjectName: "prod", ServiceName: "web"}
+	api := SelectService{ProjectName: "prod", ServiceName: "api"}
+
+	revs, err := store.List(web)
+	require.NoError(t, err)
+	assert.Empty(t, revs, "a missing file is an empty history")
+
+	for _, r := range []Revision{
+		newRevision(ServiceTypeApp, web, imageService("web:1", "A=1")),
+		newRevision(ServiceTypeApp, api, imageService("api:1", "")),
+		newRevision(ServiceTypeApp, web, imageService("web:2", "A=2")),
+	} {
+		_, err := store.Append(r)
+		require.NoError(t, err)
+	}
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	reopened := NewFileRevisionStore(path)
+	revs, err = reopened.List(web)
+	require.NoError(t, err)
+	require.Len(t, revs, 2)
+	assert.Equal(t, "web:2", revs[1].Image)
+	assert.Equal(t, 2, revs[1].Number)
+	assert.Equal(t, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), revs[1].CreatedAt)
+
+	rev, err := reopened.Get(api, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "api:1", rev.Image)
+	_, err = reopened.Get(api, 2)
+	assert.ErrorIs(t, err, ErrRevisionNotFound)
+}
@@ -0,0 +1,176 @@
+package easypanel
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// UsageRecorder accumulates container CPU and memory samples per service from
+// GetMonitorTableData snapshots. It is safe for concurrent use.
+type UsageRecorder struct {
+	mu      sync.Mutex
+	samples map[SelectService]*usageSamples
+}
+
+type usageSamples struct {
+	cpu    []float64 // cores
+	memory []float64 // MiB
+}
+
+// NewUsageRecorder returns an empty UsageRecorder.
+func NewUsageRecorder() *UsageRecorder {
+	return &UsageRecorder{samples: make(map[SelectService]*usageSamples)}
+}
+
+// Add records one snapshot. Each container contributes one sample to its
+// service, since limits apply to each replica separately.
+func (r *UsageRecorder) Add(stats []ContainerStats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range stats {
+		key := SelectService{ProjectName: c.ProjectName, ServiceName: c.ServiceName}
+		s := r.samples[key]
+		if s == nil {
+			s = &usageSamples{}
+			r.samples[key] = s
+		}
+		s.cpu = append(s.cpu, c.Stats.CPU.Percent/100)
+		s.memory = append(s.memory, float64(c.Stats.Memory.Usage)/(1<<20))
+	}
+}
+
+// Collect polls GetMonitorTableData every interval and records each snapshot
+// until count snapshots are taken or the context is done. A non-positive
+// interval defaults to 5s.
+func (r *UsageRecorder) Collect(ctx context.Context, m *MonitorService, interval time.Duration, count int) error {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+		resp, err := m.GetMonitorTableData(ctx)
+		if err != nil {
+			return err
+		}
+		r.Add(resp.Result.Data.JSON)
+	}
+	return nil
+}
+
+// RecommendOptions tunes resource recommendations. Zero values use the defaults.
+type RecommendOptions struct {
+	LimitPercentile       float64 // Percentile of usage the limit is based on; default 95
+	ReservationPercentile float64 // Percentile of usage the reservation is based on; default 50
+	Headroom              float64 // Fraction added on top of the limit percentile; default 0.2
+	MinSamples            int     // Services with fewer samples are skipped; default 10
+	MinCPU                float64 // Lower bound for CPU values in cores; default 0.05
+	MinMemoryMB           float64 // Lower bound for memory values in MiB; default 32
+}
+
+func (o RecommendOptions) withDefaults() RecommendOptions {
+	if o.LimitPercentile == 0 {
+		o.LimitPercentile = 95
+	}
+	if o.ReservationPercentile == 0 {
+		o.ReservationPercentile = 50
+	}
+	if o.Headroom == 0 {
+		o.Headroom = 0.2
+	}
+	if o.MinSamples == 0 {
+		o.MinSamples = 10
+	}
+	if o.MinCPU == 0 {
+		o.MinCPU = 0.05
+	}
+	if o.MinMemoryMB == 0 {
+		o.MinMemoryMB = 32
+	}
+	return o
+}
+
+// ResourceRecommendation suggests resources for one service.
+type ResourceRecommendation struct {
+	SelectService
+	Type           ServiceType
+	Samples        int
+	Current        Resources
+	Recommended    Resources
+	WastedCPU      float64 // Cores of current CPU limit above the recommendation
+	WastedMemoryMB float64 // MiB of current memory limit above the recommendation
+}
+
+// UpdateParams returns parameters that apply the recommendation.
+func (r ResourceRecommendation) UpdateParams() UpdateResources {
+	return UpdateResources{SelectService: r.SelectService, Resources: r.Recommended}
+}
+
+// Recommend suggests limits and reservations for each of services that has
+// enough recorded samples. Limits are the configured usage percentile plus
+// headroom; reservations are the reservation percentile. Waste is reported
+// against the service's current limits and is zero for unlimited services.
+func (r *UsageRecorder) Recommend(services []Service, opts RecommendOptions) []ResourceRecommendation {
+	opts = opts.withDefaults()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var recs []ResourceRecommendation
+	for _, svc := range services {
+		key := SelectService{ProjectName: svc.ProjectName, ServiceName: svc.name()}
+		s := r.samples[key]
+		if s == nil || len(s.cpu) < opts.MinSamples {
+			continue
+		}
+		rec := ResourceRecommendation{
+			SelectService: key,
+			Type:          svc.Type,
+			Samples:       len(s.cpu),
+			Current:       svc.Resources,
+			Recommended: Resources{
+				CPULimit:          roundCPU(math.Max(percentile(s.cpu, opts.LimitPercentile)*(1+opts.Headroom), opts.MinCPU)),
+				CPUReservation:    roundCPU(math.Max(percentile(s.cpu, opts.ReservationPercentile), opts.MinCPU)),
+				MemoryLimit:       math.Ceil(math.Max(percentile(s.memory, opts.LimitPercentile)*(1+opts.Headroom), opts.MinMemoryMB)),
+				MemoryReservation: math.Ceil(math.Max(percentile(s.memory, opts.ReservationPercentile), opts.MinMemoryMB)),
+			},
+		}
+		if svc.Resources.CPULimit > 0 {
+			rec.WastedCPU = math.Max(0, svc.Resources.CPULimit-rec.Recommended.CPULimit)
+		}
+		if svc.Resources.MemoryLimit > 0 {
+			rec.WastedMemoryMB = math.Max(0, svc.Resources.MemoryLimit-rec.Recommended.MemoryLimit)
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+// percentile returns the nearest-rank p-th percentile of values.
+func percentile(values []float64, p float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// roundCPU rounds cores up to the nearest 10 millicores, ignoring float noise
+// such as 0.36 being computed as 0.36000000000000004.
+func roundCPU(cores float64) float64 {
+	return math.Ceil(cores*100-1e-9) / 100
+}
@@ -0,0 +1,91 @@
+package easypanel
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// containerSample builds a ContainerStats entry with the given CPU percent and memory in MiB.
+func containerSample(project, service string, cpuPercent float64, memoryMB int) ContainerStats {
+	var c ContainerStats
+	c.ProjectName = project
+	c.ServiceName = service
+	c.Stats.CPU.Percent = cpuPercent
+	c.Stats.Memory.Usage = memoryMB << 20
+	return c
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{5, 1, 4, 2, 3, 10, 6, 7, 9, 8}
+	assert.Equal(t, 1.0, percentile(values, 0))
+	assert.Equal(t, 5.0, percentile(values, 50))
+	assert.Equal(t, 10.0, percentile(values, 95))
+	assert.Equal(t, 10.0, percentile(values, 100))
+	assert.Equal(t, []float64{5, 1, 4, 2, 3, 10, 6, 7, 9, 8}, values, "input must not be reordered")
+}
+
+func TestUsageRecorderRecommend(t *testing.T) {
+	rec := NewUsageRecorder()
+	for i := 1; i <= 20; i++ {
+		// web: 1%..20% CPU (0.01..0.2 cores), 100..290 MiB
+		rec.Add([]ContainerStats{
+			containerSample("proj", "web", float64(i), 90+10*i),
+			containerSample("proj", "idle", 0.1, 5),
+		})
+	}
+	rec.Add([]ContainerStats{containerSample("proj", "new", 50, 100)})
+
+	services := []Service{
+		{SelectService: SelectService{ProjectName: "proj", ServiceName: "web"}, Type: ServiceTypeApp, Resources: Resources{CPULimit: 2, MemoryLimit: 2048}},
+		{SelectService: SelectService{ProjectName: "proj"}, Name: "idle", Resources: Resources{}},
+		{SelectService: SelectService{ProjectName: "proj", ServiceName: "new"}},
+	}
+
+	recs := rec.Recommend(services, RecommendOptions{})
+	require.Len(t, recs, 2, "services with too few samples are skipped")
+
+	web := recs[0]
+	assert.Equal(t, "web", web.ServiceName)
+	assert.Equal(t, 20, web.Samples)
+	// p95 of 0.01..0.20 is 0.19 → 0.228 with headroom → 0.23
+	assert.Equal(t, 0.23, web.Recommended.CPULimit)
+	assert.Equal(t, 0.1, web.Recommended.CPUReservation)
+	// p95 of memory is 280 MiB → 336 with headroom; p50 is 190
+	assert.Equal(t, 336.0, web.Recommended.MemoryLimit)
+	assert.Equal(t, 190.0, web.Recommended.MemoryReservation)
+	assert.InDelta(t, 1.77, web.WastedCPU, 1e-9)
+	assert.Equal(t, 2048.0-336, web.WastedMemoryMB)
+	assert.NoError(t, web.Recommended.Validate())
+
+	idle := recs[1]
+	assert.Equal(t, "idle", idle.ServiceName)
+	assert.Equal(t, Resources{CPULimit: 0.05, CPUReservation: 0.05, MemoryLimit: 32, MemoryReservation: 32}, idle.Recommended, "minimums apply")
+	assert.Zero(t, idle.WastedCPU, "no waste reported for unlimited services")
+
+	params := web.UpdateParams()
+	assert.Equal(t, SelectService{ProjectName: "proj", ServiceName: "web"}, params.SelectService)
+	assert.Equal(t, web.Recommended, params.Resources)
+}
+
+func TestUsageRecorderCollect(t *testing.T) {
+	calls := 0
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/trpc/monitor.getMonitorTableData", r.URL.Path)
+		calls++
+		writeJSON(t, w, newRestResponse([]ContainerStats{containerSample("proj", "web", 10, 100)}))
+	})
+
+	rec := NewUsageRecorder()
+	err := rec.Collect(context.Background(), client.Monitor, time.Millisecond, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	recs := rec.Recommend([]Service{{SelectService: SelectService{ProjectName: "proj", ServiceName: "web"}}}, RecommendOptions{MinSamples: 3})
+	require.Len(t, recs, 1)
+	assert.Equal(t, 3, recs[0].Samples)
+}
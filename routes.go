@@ -25,6 +25,7 @@ const (
 	routeGetServiceStats     = "/api/trpc/monitor.getServiceStats"
 
 	// Settings routes
+	routeGetVersion                = "/api/trpc/settings.getVersion"
 	routeRestartEasypanel          = "/api/trpc/settings.restartEasypanel"
 	routeGetServerIp               = "/api/trpc/settings.getServerIp"
 	routeRefreshServerIp           = "/api/trpc/settings.refreshServerIp"
@@ -48,6 +49,20 @@ const (
 	routeDeleteDomain = "/api/trpc/domains.deleteDomain"
 	routeListDomains  = "/api/trpc/domains.listDomains"
 
+	// Backup destination routes
+	routeListBackupDestinations  = "/api/trpc/backups.listDestinations"
+	routeCreateBackupDestination = "/api/trpc/backups.createDestination"
+	routeUpdateBackupDestination = "/api/trpc/backups.updateDestination"
+	routeDeleteBackupDestination = "/api/trpc/backups.deleteDestination"
+	routeTestBackupDestination   = "/api/trpc/backups.testDestination"
+
+	// Volume backup routes
+	routeListVolumeBackupSchedules = "/api/trpc/volumeBackups.listSchedules"
+	routeUpdateVolumeBackup        = "/api/trpc/volumeBackups.updateSchedule"
+	routeListVolumeBackups         = "/api/trpc/volumeBackups.listBackups"
+	routeRunVolumeBackup           = "/api/trpc/volumeBackups.runBackup"
+	routeRestoreVolumeBackup       = "/api/trpc/volumeBackups.restoreBackup"
+
 	// Log routes
 	routeGetServiceLogs = "/api/trpc/logs.getServiceLogs"
 
@@ -82,6 +97,9 @@ const (
 	routeUpdateResources        = "/api/trpc/services.{type}.updateResources"
 	routeUpdateDeploy           = "/api/trpc/services.{type}.updateDeploy"
 	routeUpdateBackup           = "/api/trpc/services.{type}.updateBackup"
+	routeListBackups            = "/api/trpc/services.{type}.listBackups"
+	routeRunBackup              = "/api/trpc/services.{type}.runBackup"
+	routeRestoreBackup          = "/api/trpc/services.{type}.restoreBackup"
 	routeUpdateAdvanced         = "/api/trpc/services.{type}.updateAdvanced"
 	routeUpdateSourceInline     = "/api/trpc/services.{type}.updateSourceInline"
 )
@@ -11,7 +11,19 @@ import (
 
 // ServicesService handles service-related API operations.
 type ServicesService struct {
-	client *httpClient
+	client          *httpClient
+	domainsSvc      *DomainsService
+	revisions       RevisionStore
+	onRevisionError func(error)
+}
+
+// domains returns the client's DomainsService, so domain calls made on behalf
+// of services use the configured resolver and dialer.
+func (s *ServicesService) domains() *DomainsService {
+	if s.domainsSvc != nil {
+		return s.domainsSvc
+	}
+	return &DomainsService{client: s.client}
 }
 
 // Create creates a new service of the given type.
@@ -33,8 +45,11 @@ func (s *ServicesService) Destroy(ctx context.Context, st ServiceType, params Se
 	return s.client.post(ctx, serviceRoute(routeDestroyService, st), params, nil)
 }
 
-// Deploy triggers a deployment for a service.
+// Deploy triggers a deployment for a service. When a revision store is
+// configured, the configuration about to be deployed is first recorded as a
+// revision; see Revision.
 func (s *ServicesService) Deploy(ctx context.Context, st ServiceType, params SelectService) error {
+	s.recordRevision(ctx, st, params)
 	return s.client.post(ctx, serviceRoute(routeDeployService, st), params, nil)
 }
 
@@ -88,8 +103,14 @@ func (s *ServicesService) UpdateSourceDockerfile(ctx context.Context, st Service
 	return s.client.post(ctx, serviceRoute(routeUpdateSourceDockerfile, st), params, nil)
 }
 
-// UpdateBuild updates the build configuration for a service.
+// UpdateBuild updates the build configuration for a service. The build
+// configuration is validated locally before it is sent.
 func (s *ServicesService) UpdateBuild(ctx context.Context, st ServiceType, params UpdateBuildParams) error {
+	if params.Build != nil {
+		if err := params.Build.Validate(); err != nil {
+			return err
+		}
+	}
 	return s.client.post(ctx, serviceRoute(routeUpdateBuild, st), params, nil)
 }
 
@@ -98,13 +119,23 @@ func (s *ServicesService) UpdateEnv(ctx context.Context, st ServiceType, params
 	return s.client.post(ctx, serviceRoute(routeUpdateEnv, st), params, nil)
 }
 
-// UpdateDomains updates the domain configuration for a service.
-func (s *ServicesService) UpdateDomains(ctx context.Context, st ServiceType, params CreateServiceParams) error {
+// UpdateDomains replaces the domains of a service using the legacy per-service API.
+// Newer panels manage domains through DomainsService; use DomainsService.MigrateLegacy
+// to move existing entries over. Once Client.ServerInfo has detected such a panel,
+// UpdateDomains returns ErrUnsupported without making a request.
+func (s *ServicesService) UpdateDomains(ctx context.Context, st ServiceType, params UpdateDomainsParams) error {
+	if err := s.client.require(serviceRoute(routeUpdateDomains, st), hasLegacyDomains); err != nil {
+		return err
+	}
 	return s.client.post(ctx, serviceRoute(routeUpdateDomains, st), params, nil)
 }
 
-// UpdateRedirects updates the redirect rules for a service.
+// UpdateRedirects updates the redirect rules for a service. Every rule is
+// validated locally before the rules are sent.
 func (s *ServicesService) UpdateRedirects(ctx context.Context, st ServiceType, params UpdateRedirects) error {
+	if err := validateRedirects(params.Redirects); err != nil {
+		return err
+	}
 	return s.client.post(ctx, serviceRoute(routeUpdateRedirects, st), params, nil)
 }
 
@@ -123,8 +154,12 @@ func (s *ServicesService) UpdatePorts(ctx context.Context, st ServiceType, param
 	return s.client.post(ctx, serviceRoute(routeUpdatePorts, st), params, nil)
 }
 
-// UpdateResources updates the resource limits for a service.
+// UpdateResources updates the resource limits for a service. The resources are
+// validated locally before they are sent.
 func (s *ServicesService) UpdateResources(ctx context.Context, st ServiceType, params UpdateResources) error {
+	if err := params.Resources.Validate(); err != nil {
+		return err
+	}
 	return s.client.post(ctx, serviceRoute(routeUpdateResources, st), params, nil)
 }
 
@@ -133,17 +168,60 @@ func (s *ServicesService) UpdateDeploy(ctx context.Context, st ServiceType, para
 	return s.client.post(ctx, serviceRoute(routeUpdateDeploy, st), params, nil)
 }
 
-// UpdateBackup updates the backup configuration for a service.
+// UpdateBackup updates the backup configuration for a database service.
+// The configuration is validated locally before it is sent.
 func (s *ServicesService) UpdateBackup(ctx context.Context, st ServiceType, params UpdateBackupParams) error {
+	if err := checkBackupType(st); err != nil {
+		return err
+	}
+	if err := params.Backup.Validate(); err != nil {
+		return err
+	}
 	return s.client.post(ctx, serviceRoute(routeUpdateBackup, st), params, nil)
 }
 
+// ListBackups returns the backups taken for a database service.
+func (s *ServicesService) ListBackups(ctx context.Context, st ServiceType, params SelectService) (RestResponse[[]Backup], error) {
+	var resp RestResponse[[]Backup]
+	if err := checkBackupType(st); err != nil {
+		return resp, err
+	}
+	err := s.client.get(ctx, serviceRoute(routeListBackups, st), params, &resp)
+	return resp, err
+}
+
+// RunBackup triggers an on-demand backup of a database service.
+func (s *ServicesService) RunBackup(ctx context.Context, st ServiceType, params SelectService) error {
+	if err := checkBackupType(st); err != nil {
+		return err
+	}
+	return s.client.post(ctx, serviceRoute(routeRunBackup, st), params, nil)
+}
+
+// RestoreBackup restores a database service from a previous backup.
+func (s *ServicesService) RestoreBackup(ctx context.Context, st ServiceType, params RestoreBackupParams) error {
+	if err := checkBackupType(st); err != nil {
+		return err
+	}
+	return s.client.post(ctx, serviceRoute(routeRestoreBackup, st), params, nil)
+}
+
+// checkBackupType rejects service types that have no database backups.
+func checkBackupType(st ServiceType) error {
+	if !st.IsDatabase() {
+		return fmt.Errorf("easypanel: backups are not supported for service type %q", st)
+	}
+	return nil
+}
+
 // UpdateAdvanced updates the advanced settings for a service.
 func (s *ServicesService) UpdateAdvanced(ctx context.Context, st ServiceType, params UpdateAdvancedParams) error {
 	return s.client.post(ctx, serviceRoute(routeUpdateAdvanced, st), params, nil)
 }
 
-// UpdateSourceInline updates a compose service with inline docker-compose content.
+// UpdateSourceInline updates a compose service with inline docker-compose
+// content. The content is sent as is; check it first with ParseCompose and
+// ComposeSpec.Validate, or build it with ComposeSpec.Inline.
 func (s *ServicesService) UpdateSourceInline(ctx context.Context, st ServiceType, params UpdateSourceInline) error {
 	return s.client.post(ctx, serviceRoute(routeUpdateSourceInline, st), params, nil)
 }
@@ -163,6 +241,9 @@ func (s *ServicesService) GetServiceLogs(ctx context.Context, params SelectServi
 // StreamLogs opens a WebSocket connection to stream real-time service logs.
 // It returns a read-only channel of LogMessage. The channel is closed when the
 // context is cancelled, the server closes the connection, or a read error occurs.
+// When params.ComposeService is set, the stack's compose logs are requested and
+// only the lines logged by that compose service are delivered; the filtering
+// happens here, on the "name-1  | " prefix of each line.
 func (s *ServicesService) StreamLogs(ctx context.Context, params StreamLogsParams) (<-chan LogMessage, error) {
 	// Build WebSocket URL from the base HTTP URL.
 	u, err := url.Parse(s.client.baseURL)
@@ -181,7 +262,7 @@ func (s *ServicesService) StreamLogs(ctx context.Context, params StreamLogsParam
 	q := u.Query()
 	q.Set("token", params.Token)
 	q.Set("service", params.ProjectName+"_"+params.ServiceName)
-	q.Set("compose", strconv.FormatBool(params.Compose))
+	q.Set("compose", strconv.FormatBool(params.Compose || params.ComposeService != ""))
 	u.RawQuery = q.Encode()
 
 	conn, _, err := websocket.DefaultDialer.DialContext(ctx, u.String(), nil)
@@ -198,6 +279,12 @@ func (s *ServicesService) StreamLogs(ctx context.Context, params StreamLogsParam
 			if err := conn.ReadJSON(&msg); err != nil {
 				return
 			}
+			if params.ComposeService != "" {
+				msg.Output = filterComposeLogs(msg.Output, params.ProjectName+"_"+params.ServiceName, params.ComposeService)
+				if msg.Output == "" {
+					continue
+				}
+			}
 			select {
 			case ch <- msg:
 			case <-ctx.Done():
@@ -305,7 +305,7 @@ func TestServicesUpdateRedirects(t *testing.T) {
 }
 
 func TestServicesUpdateDomains(t *testing.T) {
-	params := CreateServiceParams{
+	params := UpdateDomainsParams{
 		SelectService: SelectService{
 			ProjectName: "proj",
 			ServiceName: "svc",
@@ -321,7 +321,7 @@ func TestServicesUpdateDomains(t *testing.T) {
 		assert.Equal(t, "/api/trpc/services.app.updateDomains", r.URL.Path)
 		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
 
-		var body CreateServiceParams
+		var body UpdateDomainsParams
 		decodeTRPCBody(t, r, &body)
 		assert.Equal(t, params, body)
 
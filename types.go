@@ -1,5 +1,7 @@
 package easypanel
 
+import "encoding/json"
+
 // RestResponse is the generic API response wrapper matching Easypanel's tRPC format.
 type RestResponse[T any] struct {
 	Result struct {
@@ -23,6 +25,12 @@ func (e *Error) Error() string {
 	return "easypanel: unknown error"
 }
 
+// Is reports whether the error matches target. An error for a procedure the
+// panel does not provide matches ErrUnsupported.
+func (e *Error) Is(target error) bool {
+	return target == ErrUnsupported && isUnknownProcedure(e)
+}
+
 // ServiceType represents the type of service in Easypanel.
 type ServiceType string
 
@@ -36,6 +44,15 @@ const (
 	ServiceTypeCompose  ServiceType = "compose"
 )
 
+// IsDatabase reports whether the service type is a managed database.
+func (st ServiceType) IsDatabase() bool {
+	switch st {
+	case ServiceTypeMySQL, ServiceTypeMariaDB, ServiceTypePostgres, ServiceTypeMongo, ServiceTypeRedis:
+		return true
+	}
+	return false
+}
+
 // LicenseType represents the license provider type.
 type LicenseType string
 
@@ -101,6 +118,13 @@ type CreateServiceParams struct {
 	Domains []DomainParams `json:"domains,omitempty"`
 }
 
+// UpdateDomainsParams contains parameters for replacing a service's domains
+// through the legacy services.{type}.updateDomains procedure.
+type UpdateDomainsParams struct {
+	SelectService
+	Domains []DomainParams `json:"domains"`
+}
+
 // DomainParams represents domain configuration for a service.
 type DomainParams struct {
 	Host  string `json:"host"`
@@ -157,7 +181,9 @@ type DeployParams struct {
 	Sysctls      []string `json:"sysctls"`
 }
 
-// Resources represents resource limits and reservations.
+// Resources represents resource limits and reservations. CPU values are in
+// cores and memory values in megabytes (MiB); zero means unset. Use
+// ResourceSpec to build one from quantities such as "500m" or "512Mi".
 type Resources struct {
 	CPULimit          float64 `json:"cpuLimit"`
 	CPUReservation    float64 `json:"cpuReservation"`
@@ -171,10 +197,42 @@ type UpdateResources struct {
 	Resources Resources `json:"resources"`
 }
 
+// BuildType selects how the panel builds an app service from its source.
+type BuildType string
+
+const (
+	BuildTypeNixpacks         BuildType = "nixpacks"
+	BuildTypeHerokuBuildpacks BuildType = "herokuBuildpacks"
+	BuildTypeDockerfile       BuildType = "dockerfile"
+	BuildTypeNone             BuildType = "none"
+)
+
+// BuildConfig is the build configuration of an app service. Type selects which
+// of the remaining fields apply: the Nixpacks fields for BuildTypeNixpacks,
+// BuildpacksBuilder for BuildTypeHerokuBuildpacks and DockerfilePath for
+// BuildTypeDockerfile. Use NixpacksBuild, BuildpacksBuild or DockerfileBuild
+// to construct one.
+type BuildConfig struct {
+	Type BuildType `json:"type"`
+
+	NixpacksVersion string `json:"nixpacksVersion,omitempty"`
+	InstallCommand  string `json:"installCommand,omitempty"`
+	BuildCommand    string `json:"buildCommand,omitempty"`
+	StartCommand    string `json:"startCommand,omitempty"`
+
+	BuildpacksBuilder string `json:"buildpacksBuilder,omitempty"`
+
+	DockerfilePath string `json:"file,omitempty"`
+}
+
 // UpdateBuildParams contains parameters for updating build configuration.
 type UpdateBuildParams struct {
 	SelectService
+	// Deprecated: BuildType only selects the build type; set Build instead.
+	// It is sent as before when set.
 	BuildType string `json:"type,omitempty"` // "nixpacks", "herokuBuildpacks", "dockerfile", "none"
+	// Build is the typed build configuration. It is only sent when set.
+	Build *BuildConfig `json:"build,omitempty"`
 }
 
 // GitParams represents Git source configuration.
@@ -200,11 +258,50 @@ type DockerImageParams struct {
 }
 
 // ServiceSource represents the source configuration of a service.
+// GitParams and GithubParams share the repo, branch and path keys; both are
+// filled when a source is decoded.
 type ServiceSource struct {
-	AutoDeploy bool `json:"autoDeploy"`
+	Type       string `json:"type,omitempty"` // "github", "git", "image", "dockerfile"
+	AutoDeploy bool   `json:"autoDeploy"`
 	GitParams
 	GithubParams
 	DockerImageParams
+	Dockerfile     string `json:"dockerfile,omitempty"`
+	ComposeFile    string `json:"composeFile,omitempty"`    // Compose services only
+	ComposeContent string `json:"composeContent,omitempty"` // Inline compose services only
+}
+
+// serviceSourceJSON gives the shared repo, branch and path keys a single field
+// each; encoding/json would otherwise drop the conflicting embedded fields.
+type serviceSourceJSON struct {
+	*serviceSourceFields
+	Repo   string `json:"repo,omitempty"`
+	Branch string `json:"branch,omitempty"`
+	Path   string `json:"path,omitempty"`
+}
+
+type serviceSourceFields ServiceSource
+
+// MarshalJSON encodes the GitHub repo, branch and path when Owner is set and
+// the Git ones otherwise.
+func (s ServiceSource) MarshalJSON() ([]byte, error) {
+	out := serviceSourceJSON{serviceSourceFields: (*serviceSourceFields)(&s), Repo: s.GitParams.Repo, Branch: s.GitParams.Branch, Path: s.GitParams.Path}
+	if s.Owner != "" {
+		out.Repo, out.Branch, out.Path = s.GithubParams.Repo, s.GithubParams.Branch, s.GithubParams.Path
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes a source, copying repo, branch and path into both
+// GitParams and GithubParams.
+func (s *ServiceSource) UnmarshalJSON(data []byte) error {
+	in := serviceSourceJSON{serviceSourceFields: (*serviceSourceFields)(s)}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	s.GitParams = GitParams{Repo: in.Repo, Branch: in.Branch, Path: in.Path}
+	s.GithubParams.Repo, s.GithubParams.Branch, s.GithubParams.Path = in.Repo, in.Branch, in.Path
+	return nil
 }
 
 // UpdateGithub contains parameters for updating GitHub source.
@@ -273,10 +370,37 @@ type UpdateAdvancedParams struct {
 	Hostname string `json:"hostname,omitempty"`
 }
 
+// BackupConfig represents the scheduled backup configuration of a database service.
+type BackupConfig struct {
+	Enabled       bool   `json:"enabled"`
+	Schedule      string `json:"schedule"`      // Cron expression, e.g. "0 3 * * *"
+	DestinationID string `json:"destinationId"` // ID of a configured backup destination
+	Prefix        string `json:"prefix,omitempty"`
+	Retention     int    `json:"retention,omitempty"` // Number of backups to keep, 0 keeps all
+}
+
 // UpdateBackupParams contains parameters for backup configuration.
 type UpdateBackupParams struct {
 	SelectService
-	// Backup-specific fields can be extended as needed
+	Backup BackupConfig `json:"backup"`
+}
+
+// Backup represents a single database backup produced by a service.
+type Backup struct {
+	ID            string `json:"id"`
+	ProjectName   string `json:"projectName"`
+	ServiceName   string `json:"serviceName"`
+	DestinationID string `json:"destinationId"`
+	Key           string `json:"key"` // Object key of the backup file at the destination
+	Size          int64  `json:"size"`
+	Status        string `json:"status"` // "running", "done", "error" or "failed"
+	CreatedAt     string `json:"createdAt"`
+}
+
+// RestoreBackupParams contains parameters for restoring a database backup.
+type RestoreBackupParams struct {
+	SelectService
+	BackupID string `json:"backupId"`
 }
 
 // Service represents a full service configuration.
@@ -297,7 +421,18 @@ type Service struct {
 	ExposedPort   int              `json:"exposedPort,omitempty"`
 	DeploymentURL string           `json:"deploymentUrl,omitempty"`
 	Source        *ServiceSource   `json:"source,omitempty"`
+	Build         *BuildConfig     `json:"build,omitempty"`
 	Resources     Resources        `json:"resources"`
+	Backup        *BackupConfig    `json:"backup,omitempty"`
+}
+
+// name returns the service name. Some endpoints return it in Name instead of
+// ServiceName.
+func (s Service) name() string {
+	if s.ServiceName != "" {
+		return s.ServiceName
+	}
+	return s.Name
 }
 
 // --- Monitor Types ---
@@ -446,27 +581,50 @@ type LetsEncryptParams struct {
 
 // --- Domain Types (newer Easypanel API) ---
 
+// DomainDestinationType represents where a domain routes traffic to.
+type DomainDestinationType string
+
+const (
+	DomainDestinationService DomainDestinationType = "service"
+)
+
+// CertificateResolver represents the Traefik certificate resolver used for HTTPS domains.
+type CertificateResolver string
+
+const (
+	CertificateResolverNone        CertificateResolver = ""
+	CertificateResolverLetsEncrypt CertificateResolver = "letsencrypt" // HTTP-01 challenge, no wildcards
+)
+
+// DomainProtocol represents the protocol used to reach the destination service.
+type DomainProtocol string
+
+const (
+	DomainProtocolHTTP  DomainProtocol = "http"
+	DomainProtocolHTTPS DomainProtocol = "https"
+)
+
 // ServiceDestination represents the target service for a domain.
 type ServiceDestination struct {
-	Protocol       string `json:"protocol"`
-	Port           int    `json:"port"`
-	Path           string `json:"path"`
-	ProjectName    string `json:"projectName"`
-	ServiceName    string `json:"serviceName"`
-	ComposeService string `json:"composeService,omitempty"`
+	Protocol       DomainProtocol `json:"protocol"`
+	Port           int            `json:"port"`
+	Path           string         `json:"path"`
+	ProjectName    string         `json:"projectName"`
+	ServiceName    string         `json:"serviceName"`
+	ComposeService string         `json:"composeService,omitempty"`
 }
 
 // Domain represents a domain configuration in the newer Easypanel API.
 type Domain struct {
-	ID                  string              `json:"id"`
-	HTTPS               bool                `json:"https"`
-	Host                string              `json:"host"`
-	Path                string              `json:"path"`
-	Middlewares         []string            `json:"middlewares"`
-	CertificateResolver string              `json:"certificateResolver"`
-	Wildcard            bool                `json:"wildcard"`
-	DestinationType     string              `json:"destinationType"`
-	ServiceDestination  *ServiceDestination `json:"serviceDestination,omitempty"`
+	ID                  string                `json:"id"`
+	HTTPS               bool                  `json:"https"`
+	Host                string                `json:"host"`
+	Path                string                `json:"path"`
+	Middlewares         []string              `json:"middlewares"` // Traefik middleware names, e.g. "auth@file"
+	CertificateResolver CertificateResolver   `json:"certificateResolver"`
+	Wildcard            bool                  `json:"wildcard"`
+	DestinationType     DomainDestinationType `json:"destinationType"`
+	ServiceDestination  *ServiceDestination   `json:"serviceDestination,omitempty"`
 }
 
 // CreateDomainParams contains parameters for creating a domain.
@@ -486,6 +644,103 @@ type ListDomainsParams struct {
 	ServiceName string `json:"serviceName"`
 }
 
+// DomainMigration describes the outcome of migrating one legacy domain entry.
+type DomainMigration struct {
+	Legacy  DomainParams
+	Domain  Domain
+	Skipped bool   // A matching domain already exists
+	Reason  string // Why the entry was skipped, if it was
+	Err     error  // Error returned by DomainsService.Create, if any
+}
+
+// DomainMigrationReport summarizes a legacy domain migration for one service.
+type DomainMigrationReport struct {
+	ProjectName string
+	ServiceName string
+	DryRun      bool
+	Domains     []DomainMigration
+}
+
+// --- Backup Destination Types ---
+
+// BackupDestinationType represents the storage backend of a backup destination.
+type BackupDestinationType string
+
+const (
+	BackupDestinationS3 BackupDestinationType = "s3"
+)
+
+// S3Credentials holds the connection settings for an S3-compatible bucket.
+type S3Credentials struct {
+	Endpoint        string `json:"endpoint"` // e.g. "https://s3.amazonaws.com" or "http://localhost:9000"
+	Region          string `json:"region,omitempty"`
+	Bucket          string `json:"bucket"`
+	AccessKeyID     string `json:"accessKeyId"`
+	SecretAccessKey string `json:"secretAccessKey"`
+	ForcePathStyle  bool   `json:"forcePathStyle,omitempty"` // Required by most self-hosted stores such as MinIO
+}
+
+// BackupDestination represents a storage location that backups are uploaded to.
+type BackupDestination struct {
+	ID        string                `json:"id,omitempty"`
+	Name      string                `json:"name"`
+	Type      BackupDestinationType `json:"type"`
+	S3        *S3Credentials        `json:"s3,omitempty"`
+	CreatedAt string                `json:"createdAt,omitempty"`
+}
+
+// CreateBackupDestinationParams contains parameters for creating a backup destination.
+type CreateBackupDestinationParams = BackupDestination
+
+// UpdateBackupDestinationParams contains parameters for updating a backup destination.
+type UpdateBackupDestinationParams = BackupDestination
+
+// DeleteBackupDestinationParams contains parameters for deleting a backup destination.
+type DeleteBackupDestinationParams struct {
+	ID string `json:"id"`
+}
+
+// BackupDestinationTestResult reports whether the panel could reach a destination.
+type BackupDestinationTestResult struct {
+	OK      bool   `json:"ok"`
+	Message string `json:"message,omitempty"`
+}
+
+// --- Volume Backup Types ---
+
+// VolumeSelect identifies a named volume mount of a service.
+type VolumeSelect struct {
+	SelectService
+	VolumeName string `json:"volumeName"` // MountEntry.Name of a "volume" mount
+}
+
+// VolumeBackupSchedule represents the scheduled backup configuration of a volume mount.
+type VolumeBackupSchedule struct {
+	ID          string `json:"id,omitempty"`
+	ProjectName string `json:"projectName"`
+	ServiceName string `json:"serviceName"`
+	VolumeName  string `json:"volumeName"`
+	BackupConfig
+}
+
+// UpdateVolumeBackupParams contains parameters for configuring a volume backup schedule.
+type UpdateVolumeBackupParams struct {
+	VolumeSelect
+	Backup BackupConfig `json:"backup"`
+}
+
+// VolumeBackup represents a single backup of a volume mount.
+type VolumeBackup struct {
+	Backup
+	VolumeName string `json:"volumeName"`
+}
+
+// RestoreVolumeBackupParams contains parameters for restoring a volume backup.
+type RestoreVolumeBackupParams struct {
+	VolumeSelect
+	BackupID string `json:"backupId"`
+}
+
 // --- Compose Service Types ---
 
 // UpdateSourceInline contains parameters for updating a compose service with inline content.
@@ -560,4 +815,8 @@ type StreamLogsParams struct {
 	ServiceName string
 	Token       string // Service deploy token (Service.Token)
 	Compose     bool
+	// ComposeService limits a compose stream to one service of the stack. It
+	// implies Compose. The panel streams the whole stack and the lines of
+	// other services are dropped by the client.
+	ComposeService string
 }